@@ -0,0 +1,133 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConversionDataAnnotation is the annotation that holds the fields of the spec
+// of the Hub version that have no equivalent in this version, so that they
+// survive a round trip. It is omitted when there are none.
+const ConversionDataAnnotation = "ocean.spot.io/conversion-data"
+
+// ConvertTo converts this OceanComponent to the Hub version (v1beta1).
+func (src *OceanComponent) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*oceanv1beta1.OceanComponent)
+
+	// ObjectMeta
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	dst.Spec = oceanv1beta1.OceanComponentSpec{}
+	if data, ok := dst.Annotations[ConversionDataAnnotation]; ok {
+		if err := json.Unmarshal([]byte(data), &dst.Spec); err != nil {
+			return fmt.Errorf("invalid conversion data: %w", err)
+		}
+		delete(dst.Annotations, ConversionDataAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	// Spec
+	dst.Spec.Type = oceanv1beta1.OceanComponentType(src.Spec.Type)
+	dst.Spec.Name = oceanv1beta1.OceanComponentName(src.Spec.Name)
+	dst.Spec.State = oceanv1beta1.OceanComponentState(src.Spec.State)
+	dst.Spec.URL = src.Spec.URL
+	dst.Spec.Version = src.Spec.Version
+	dst.Spec.Values = nil
+	if src.Spec.Values != "" {
+		raw, err := yamlutil.ToJSON([]byte(src.Spec.Values))
+		if err != nil {
+			return fmt.Errorf("invalid values configuration: %w", err)
+		}
+		if string(raw) != "null" {
+			dst.Spec.Values = &apiextensionsv1.JSON{Raw: raw}
+		}
+	}
+
+	// Status
+	dst.Status.Properties = src.Status.Properties
+	dst.Status.Conditions = nil
+	for _, c := range src.Status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, oceanv1beta1.OceanComponentCondition{
+			Type:               oceanv1beta1.OceanComponentConditionType(c.Type),
+			Status:             c.Status,
+			LastUpdateTime:     c.LastUpdateTime,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+func (dst *OceanComponent) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*oceanv1beta1.OceanComponent)
+
+	// ObjectMeta
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	delete(dst.Annotations, ConversionDataAnnotation)
+	if hubOnly := hubOnlySpec(&src.Spec); hubOnly != nil {
+		data, err := json.Marshal(hubOnly)
+		if err != nil {
+			return fmt.Errorf("unable to marshal conversion data: %w", err)
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = make(map[string]string, 1)
+		}
+		dst.Annotations[ConversionDataAnnotation] = string(data)
+	}
+
+	// Spec
+	dst.Spec.Type = OceanComponentType(src.Spec.Type)
+	dst.Spec.Name = OceanComponentName(src.Spec.Name)
+	dst.Spec.State = OceanComponentState(src.Spec.State)
+	dst.Spec.URL = src.Spec.URL
+	dst.Spec.Version = src.Spec.Version
+	dst.Spec.Values = ""
+	if src.Spec.Values != nil && len(src.Spec.Values.Raw) > 0 {
+		// JSON is a subset of YAML, so the raw values are used as is.
+		dst.Spec.Values = string(src.Spec.Values.Raw)
+	}
+
+	// Status
+	dst.Status.Properties = src.Status.Properties
+	dst.Status.Conditions = nil
+	for _, c := range src.Status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, OceanComponentCondition{
+			Type:               OceanComponentConditionType(c.Type),
+			Status:             c.Status,
+			LastUpdateTime:     c.LastUpdateTime,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+
+	return nil
+}
+
+// hubOnlySpec returns a copy of the given spec holding only the fields that
+// have no equivalent in this version, or nil if none is set.
+func hubOnlySpec(spec *oceanv1beta1.OceanComponentSpec) *oceanv1beta1.OceanComponentSpec {
+	out := spec.DeepCopy()
+	out.Type = ""
+	out.Name = ""
+	out.State = ""
+	out.URL = ""
+	out.Version = ""
+	out.Values = nil
+	if equality.Semantic.DeepEqual(out, &oceanv1beta1.OceanComponentSpec{}) {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConvertTo(t *testing.T) {
+	src := &OceanComponent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ocean-controller",
+			Namespace: NamespaceSystem,
+		},
+		Spec: OceanComponentSpec{
+			Type:    OceanComponentTypeHelm,
+			Name:    LegacyOceanControllerComponentName,
+			State:   OceanComponentStatePresent,
+			URL:     "https://spotinst.github.io/spotinst-kubernetes-helm-charts",
+			Version: "1.0.95",
+			Values: `
+secret:
+  enabled: true
+`,
+		},
+		Status: OceanComponentStatus{
+			Conditions: []OceanComponentCondition{
+				{
+					Type:   OceanComponentConditionTypeAvailable,
+					Status: corev1.ConditionTrue,
+					Reason: "Installed",
+				},
+			},
+		},
+	}
+
+	t.Run("whenSuccessful", func(tt *testing.T) {
+		dst := new(oceanv1beta1.OceanComponent)
+		assert.NoError(tt, src.ConvertTo(dst))
+
+		assert.Equal(tt, src.Name, dst.Name)
+		assert.Equal(tt, oceanv1beta1.OceanComponentTypeHelm, dst.Spec.Type)
+		assert.Equal(tt, oceanv1beta1.LegacyOceanControllerComponentName, dst.Spec.Name)
+		assert.Equal(tt, oceanv1beta1.OceanComponentStatePresent, dst.Spec.State)
+		assert.Equal(tt, src.Spec.URL, dst.Spec.URL)
+		assert.Equal(tt, src.Spec.Version, dst.Spec.Version)
+		assert.JSONEq(tt, `{"secret":{"enabled":true}}`, string(dst.Spec.Values.Raw))
+		assert.Len(tt, dst.Status.Conditions, 1)
+		assert.Equal(tt, oceanv1beta1.OceanComponentConditionTypeAvailable, dst.Status.Conditions[0].Type)
+	})
+
+	t.Run("whenValuesEmpty", func(tt *testing.T) {
+		in := src.DeepCopy()
+		in.Spec.Values = ""
+
+		dst := new(oceanv1beta1.OceanComponent)
+		assert.NoError(tt, in.ConvertTo(dst))
+		assert.Nil(tt, dst.Spec.Values)
+	})
+
+	t.Run("whenValuesInvalid", func(tt *testing.T) {
+		in := src.DeepCopy()
+		in.Spec.Values = "secret: [enabled"
+
+		dst := new(oceanv1beta1.OceanComponent)
+		assert.Error(tt, in.ConvertTo(dst))
+	})
+}
+
+func TestConvertFrom(t *testing.T) {
+	src := &oceanv1beta1.OceanComponent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metrics-server",
+			Namespace: oceanv1beta1.NamespaceSystem,
+		},
+		Spec: oceanv1beta1.OceanComponentSpec{
+			Type:    oceanv1beta1.OceanComponentTypeHelm,
+			Name:    oceanv1beta1.MetricsServerComponentName,
+			State:   oceanv1beta1.OceanComponentStateAbsent,
+			URL:     "https://charts.helm.sh/stable",
+			Version: "2.8.8",
+			Values:  &apiextensionsv1.JSON{Raw: []byte(`{"args":["--kubelet-insecure-tls"]}`)},
+		},
+	}
+
+	t.Run("whenSuccessful", func(tt *testing.T) {
+		dst := new(OceanComponent)
+		assert.NoError(tt, dst.ConvertFrom(src))
+
+		assert.Equal(tt, src.Name, dst.Name)
+		assert.Equal(tt, MetricsServerComponentName, dst.Spec.Name)
+		assert.Equal(tt, OceanComponentStateAbsent, dst.Spec.State)
+		assert.Equal(tt, `{"args":["--kubelet-insecure-tls"]}`, dst.Spec.Values)
+		assert.NotContains(tt, dst.Annotations, ConversionDataAnnotation)
+	})
+
+	t.Run("whenRoundTrip", func(tt *testing.T) {
+		spoke := new(OceanComponent)
+		assert.NoError(tt, spoke.ConvertFrom(src))
+
+		hub := new(oceanv1beta1.OceanComponent)
+		assert.NoError(tt, spoke.ConvertTo(hub))
+		assert.Equal(tt, src.Spec, hub.Spec)
+		assert.NotContains(tt, hub.Annotations, ConversionDataAnnotation)
+	})
+
+	t.Run("whenRoundTripWithHubOnlyFields", func(tt *testing.T) {
+		in := src.DeepCopy()
+		in.Spec.ValuesFrom = []oceanv1beta1.ValuesReference{
+			{
+				Kind:       oceanv1beta1.ValuesReferenceKindSecret,
+				Name:       "metrics-server-values",
+				TargetPath: "apiService.caBundle",
+			},
+		}
+
+		spoke := new(OceanComponent)
+		assert.NoError(tt, spoke.ConvertFrom(in))
+		assert.NotContains(tt, spoke.Annotations[ConversionDataAnnotation], src.Spec.URL)
+
+		hub := new(oceanv1beta1.OceanComponent)
+		assert.NoError(tt, spoke.ConvertTo(hub))
+		assert.Equal(tt, in.Spec, hub.Spec)
+	})
+
+	t.Run("whenStatusNotPreserved", func(tt *testing.T) {
+		in := src.DeepCopy()
+		in.Status = oceanv1beta1.OceanComponentStatus{
+			Properties:         map[string]string{"chart": "metrics-server"},
+			ObservedGeneration: 3,
+			Revision:           2,
+		}
+
+		spoke := new(OceanComponent)
+		assert.NoError(tt, spoke.ConvertFrom(in))
+		assert.NotContains(tt, spoke.Annotations, ConversionDataAnnotation)
+
+		hub := new(oceanv1beta1.OceanComponent)
+		assert.NoError(tt, spoke.ConvertTo(hub))
+		assert.Equal(tt, in.Status.Properties, hub.Status.Properties)
+		assert.Zero(tt, hub.Status.Revision)
+	})
+
+	t.Run("whenLegacyConversionData", func(tt *testing.T) {
+		spoke := &OceanComponent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "metrics-server",
+				Annotations: map[string]string{
+					ConversionDataAnnotation: `{"type":"Helm","name":"metrics-server","state":"Absent","url":"https://charts.helm.sh/stable","version":"2.8.8","suspend":true}`,
+				},
+			},
+			Spec: OceanComponentSpec{
+				Type:    OceanComponentTypeHelm,
+				Name:    MetricsServerComponentName,
+				State:   OceanComponentStateAbsent,
+				URL:     "https://charts.helm.sh/stable",
+				Version: "2.8.8",
+			},
+		}
+
+		hub := new(oceanv1beta1.OceanComponent)
+		assert.NoError(tt, spoke.ConvertTo(hub))
+		assert.True(tt, hub.Spec.Suspend)
+	})
+}
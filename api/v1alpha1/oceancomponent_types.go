@@ -100,7 +100,11 @@ type OceanComponentStatus struct {
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=oc,path=oceancomponents
+// +kubebuilder:printcolumn:name="Component",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.spec.state`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // OceanComponent is the Schema for the OceanComponent API
 type OceanComponent struct {
@@ -0,0 +1,79 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package v1beta1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CatalogEntry describes a known component and the defaults used for it.
+type CatalogEntry struct {
+	// Name is the resource name of the component (e.g. "ocean-controller").
+	Name OceanComponentName
+	// Spec holds the defaults of the component. Spec.Name is the name of the
+	// component archive file, and may differ from Name.
+	Spec OceanComponentSpec
+}
+
+// catalog is the list of known components.
+var catalog = []CatalogEntry{
+	{
+		Name: OceanControllerComponentName,
+		Spec: OceanComponentSpec{
+			Type:    OceanComponentTypeHelm,
+			Name:    LegacyOceanControllerComponentName,
+			State:   OceanComponentStatePresent,
+			URL:     "https://spotinst.github.io/spotinst-kubernetes-helm-charts",
+			Version: "1.0.95",
+			Values: &apiextensionsv1.JSON{
+				Raw: []byte(`{"secret":{"enabled":true},"metrics-server":{"deployChart":false}}`),
+			},
+			// the bundled metrics-server is disabled in favor of the component
+			DependsOn: []string{MetricsServerComponentName.String()},
+			HealthChecks: []HealthCheck{
+				{
+					Kind:      HealthCheckKindDeployment,
+					Name:      LegacyOceanControllerComponentName.String(),
+					Namespace: metav1.NamespaceSystem,
+				},
+			},
+		},
+	},
+	{
+		Name: MetricsServerComponentName,
+		Spec: OceanComponentSpec{
+			Type:    OceanComponentTypeHelm,
+			Name:    MetricsServerComponentName,
+			State:   OceanComponentStatePresent,
+			URL:     "https://charts.helm.sh/stable",
+			Version: "2.8.8",
+			HealthChecks: []HealthCheck{
+				{
+					Kind: HealthCheckKindDeployment,
+					Name: MetricsServerComponentName.String(),
+				},
+			},
+		},
+	},
+}
+
+// Catalog returns a copy of the list of known components.
+func Catalog() []CatalogEntry {
+	entries := make([]CatalogEntry, len(catalog))
+	for i := range catalog {
+		entries[i] = *catalog[i].DeepCopy()
+	}
+	return entries
+}
+
+// LookupCatalog returns a copy of the catalog entry of the component with the
+// given name, which is either its resource name or the name of its archive file.
+func LookupCatalog(name OceanComponentName) (*CatalogEntry, bool) {
+	for i := range catalog {
+		if catalog[i].Name == name || catalog[i].Spec.Name == name {
+			return catalog[i].DeepCopy(), true
+		}
+	}
+	return nil, false
+}
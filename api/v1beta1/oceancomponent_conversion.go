@@ -0,0 +1,45 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package v1beta1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Hub marks this type as a conversion hub.
+func (*OceanComponent) Hub() {}
+
+// NormalizeValues converts values stored by the v1alpha1 API as a YAML string,
+// which the API server serves unconverted when the CRD has no conversion
+// webhook, to the structured values of this version. It reports whether the
+// values were converted.
+func (r *OceanComponent) NormalizeValues() (bool, error) {
+	if r.Spec.Values == nil || !bytes.HasPrefix(bytes.TrimSpace(r.Spec.Values.Raw), []byte(`"`)) {
+		return false, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(r.Spec.Values.Raw, &s); err != nil {
+		return false, fmt.Errorf("invalid values: %w", err)
+	}
+	data, err := yamlutil.ToJSON([]byte(s))
+	if err != nil {
+		return false, fmt.Errorf("invalid values: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) == 0 || bytes.Equal(data, []byte("null")):
+		r.Spec.Values = nil
+	case bytes.HasPrefix(data, []byte("{")):
+		r.Spec.Values = &apiextensionsv1.JSON{Raw: data}
+	default:
+		return false, fmt.Errorf("invalid values: expected a map, got %s", data)
+	}
+
+	return true, nil
+}
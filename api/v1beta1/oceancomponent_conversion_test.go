@@ -0,0 +1,41 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestNormalizeValues(t *testing.T) {
+	t.Run("whenLegacyValues", func(tt *testing.T) {
+		in := &OceanComponent{Spec: OceanComponentSpec{
+			Values: &apiextensionsv1.JSON{Raw: []byte(`"secret:\n  enabled: true\n"`)},
+		}}
+		changed, err := in.NormalizeValues()
+		assert.NoError(tt, err)
+		assert.True(tt, changed)
+		assert.JSONEq(tt, `{"secret":{"enabled":true}}`, string(in.Spec.Values.Raw))
+	})
+
+	t.Run("whenLegacyValuesEmpty", func(tt *testing.T) {
+		in := &OceanComponent{Spec: OceanComponentSpec{
+			Values: &apiextensionsv1.JSON{Raw: []byte(`""`)},
+		}}
+		changed, err := in.NormalizeValues()
+		assert.NoError(tt, err)
+		assert.True(tt, changed)
+		assert.Nil(tt, in.Spec.Values)
+	})
+
+	t.Run("whenStructuredValues", func(tt *testing.T) {
+		in := &OceanComponent{Spec: OceanComponentSpec{
+			Values: &apiextensionsv1.JSON{Raw: []byte(`{"secret":{"enabled":true}}`)},
+		}}
+		changed, err := in.NormalizeValues()
+		assert.NoError(tt, err)
+		assert.False(tt, changed)
+	})
+}
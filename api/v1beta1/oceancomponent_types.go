@@ -0,0 +1,681 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceSystem is the system namespace where we place Ocean components.
+const NamespaceSystem = "spot-system"
+
+// SuspendAnnotation suspends the reconciliation of an OceanComponent when set
+// to "true", like Spec.Suspend. It is meant for emergencies.
+const SuspendAnnotation = "ocean.spot.io/suspend"
+
+// OceanComponentType represents the type of OceanComponent.
+type OceanComponentType string
+
+// These are valid component types.
+const (
+	OceanComponentTypeHelm      OceanComponentType = "Helm"
+	OceanComponentTypeManifest  OceanComponentType = "Manifest"
+	OceanComponentTypeKustomize OceanComponentType = "Kustomize"
+	OceanComponentTypePlugin    OceanComponentType = "Plugin"
+)
+
+func (x OceanComponentType) String() string { return string(x) }
+
+// OceanComponentState represents the state of OceanComponent.
+type OceanComponentState string
+
+// These are valid component states.
+const (
+	OceanComponentStatePresent OceanComponentState = "Present"
+	OceanComponentStateAbsent  OceanComponentState = "Absent"
+)
+
+func (x OceanComponentState) String() string { return string(x) }
+
+// OceanComponentName represents the name of OceanComponent.
+type OceanComponentName string
+
+// These are valid component names.
+const (
+	MetricsServerComponentName         OceanComponentName = "metrics-server"
+	OceanControllerComponentName       OceanComponentName = "ocean-controller"
+	LegacyOceanControllerComponentName OceanComponentName = "spotinst-kubernetes-cluster-controller"
+)
+
+func (x OceanComponentName) String() string { return string(x) }
+
+// OceanComponentConditionType represents the type of OceanComponentCondition.
+type OceanComponentConditionType string
+
+// These are valid component conditions.
+const (
+	// OceanComponentConditionTypeAvailable means the application is available.
+	OceanComponentConditionTypeAvailable OceanComponentConditionType = "Available"
+	// OceanComponentConditionTypeProgressing means the component is progressing.
+	OceanComponentConditionTypeProgressing OceanComponentConditionType = "Progressing"
+	// OceanComponentConditionTypeDegraded indicates the component is in a temporary degraded state.
+	OceanComponentConditionTypeDegraded OceanComponentConditionType = "Degraded"
+	// OceanComponentConditionTypeFailure indicates a significant error conditions.
+	OceanComponentConditionTypeFailure OceanComponentConditionType = "Failing"
+	// OceanComponentConditionTypeReady summarises the other conditions and
+	// indicates whether the component is ready for use.
+	OceanComponentConditionTypeReady OceanComponentConditionType = "Ready"
+	// OceanComponentConditionTypeUpgradePending indicates an upgrade that is
+	// held back by the upgrade policy, the maintenance windows, or a rollback
+	// of the same upgrade.
+	OceanComponentConditionTypeUpgradePending OceanComponentConditionType = "UpgradePending"
+	// OceanComponentConditionTypeSuspended indicates the reconciliation of
+	// the component is suspended.
+	OceanComponentConditionTypeSuspended OceanComponentConditionType = "Suspended"
+	// OceanComponentConditionTypeDrifted indicates the live state of the
+	// objects created by the component differs from their desired state.
+	OceanComponentConditionTypeDrifted OceanComponentConditionType = "Drifted"
+)
+
+func (x OceanComponentConditionType) String() string { return string(x) }
+
+// OceanComponentCondition describes the state of a deployment at a certain point.
+type OceanComponentCondition struct {
+	// Type of deployment condition.
+	Type OceanComponentConditionType `json:"type" protobuf:"bytes,1,opt,name=type,casttype=OceanComponentConditionType"`
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status" protobuf:"bytes,2,opt,name=status,casttype=k8s.io/api/core/v1.ConditionStatus"`
+	// The last time this condition was updated.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty" protobuf:"bytes,6,opt,name=lastUpdateTime"`
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty" protobuf:"bytes,7,opt,name=lastTransitionTime"`
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty" protobuf:"bytes,4,opt,name=reason"`
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty" protobuf:"bytes,5,opt,name=message"`
+	// ObservedGeneration is the generation of the component the condition
+	// was set for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" protobuf:"varint,8,opt,name=observedGeneration"`
+}
+
+// ValuesReferenceKind represents the kind of object referenced by ValuesReference.
+type ValuesReferenceKind string
+
+// These are valid values reference kinds.
+const (
+	ValuesReferenceKindConfigMap ValuesReferenceKind = "ConfigMap"
+	ValuesReferenceKindSecret    ValuesReferenceKind = "Secret"
+)
+
+func (x ValuesReferenceKind) String() string { return string(x) }
+
+// DefaultValuesKey is the data key used when a ValuesReference does not specify one.
+const DefaultValuesKey = "values.yaml"
+
+// ValuesReference contains a reference to a ConfigMap or Secret key holding
+// values for the OceanComponent.
+type ValuesReference struct {
+	// Kind of the values referent, one of ["ConfigMap", "Secret"].
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind ValuesReferenceKind `json:"kind"`
+	// Name of the values referent, in the namespace of the OceanComponent.
+	Name string `json:"name"`
+	// Key is the data key where the values can be found. Defaults to "values.yaml".
+	// +optional
+	Key string `json:"key,omitempty"`
+	// TargetPath is the dot-separated path where the value of the key is placed
+	// as a string (e.g. "spotinst.token"). When omitted, the value is parsed as
+	// YAML and merged at the root of the values.
+	// +optional
+	TargetPath string `json:"targetPath,omitempty"`
+	// Optional marks the reference as optional. A missing referent or key is
+	// ignored instead of failing the reconciliation.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// HealthCheckKind represents the kind of objects checked by a HealthCheck.
+type HealthCheckKind string
+
+// These are valid health check kinds.
+const (
+	HealthCheckKindDeployment  HealthCheckKind = "Deployment"
+	HealthCheckKindDaemonSet   HealthCheckKind = "DaemonSet"
+	HealthCheckKindStatefulSet HealthCheckKind = "StatefulSet"
+	HealthCheckKindJob         HealthCheckKind = "Job"
+	HealthCheckKindAPIService  HealthCheckKind = "APIService"
+)
+
+func (x HealthCheckKind) String() string { return string(x) }
+
+// HealthCheck describes a check of objects created by the OceanComponent,
+// used to determine whether the component is available.
+type HealthCheck struct {
+	// Kind of the checked objects, one of ["Deployment", "DaemonSet",
+	// "StatefulSet", "Job", "APIService"].
+	// +kubebuilder:validation:Enum=Deployment;DaemonSet;StatefulSet;Job;APIService
+	Kind HealthCheckKind `json:"kind"`
+	// Name of the checked object. Exactly one of Name and Selector must be set.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Selector selects the checked objects by their labels.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Namespace of the checked objects. Defaults to the target namespace of
+	// the OceanComponent, and is ignored for APIServices.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// MinAvailable is the minimum number of available replicas (or succeeded
+	// completions, for Jobs) of each checked object. Objects with fewer
+	// available replicas than desired, but at least MinAvailable, are
+	// degraded. Defaults to the desired number of replicas.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinAvailable *int32 `json:"minAvailable,omitempty"`
+}
+
+// UpgradePolicy represents the kind of upgrades applied automatically to
+// an OceanComponent.
+type UpgradePolicy string
+
+// These are valid upgrade policies.
+const (
+	// UpgradePolicyManual applies no automatic upgrades.
+	UpgradePolicyManual UpgradePolicy = "Manual"
+	// UpgradePolicyPatch applies automatic upgrades to newer patch versions.
+	UpgradePolicyPatch UpgradePolicy = "Patch"
+	// UpgradePolicyMinor applies automatic upgrades to newer minor and patch versions.
+	UpgradePolicyMinor UpgradePolicy = "Minor"
+)
+
+func (x UpgradePolicy) String() string { return string(x) }
+
+// Weekday represents a day of the week.
+// +kubebuilder:validation:Enum=Sunday;Monday;Tuesday;Wednesday;Thursday;Friday;Saturday
+type Weekday string
+
+func (x Weekday) String() string { return string(x) }
+
+// TimeOfDayLayout is the layout of the start and end times of MaintenanceWindow.
+const TimeOfDayLayout = "15:04"
+
+// MaintenanceWindow describes a recurring time range during which upgrades
+// of the OceanComponent may run.
+type MaintenanceWindow struct {
+	// Days of the week on which the window opens (e.g. ["Saturday", "Sunday"]).
+	// Defaults to every day.
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+	// Start is the time of day the window opens, in "HH:MM" format.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// End is the time of day the window closes, in "HH:MM" format. An end
+	// time not after the start time closes the window on the next day.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+	// TimeZone is the IANA time zone name of Start and End (e.g.
+	// "Europe/London"). Defaults to "UTC".
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// DriftPolicy represents how drift of the objects created by an OceanComponent
+// from their desired state is handled.
+type DriftPolicy string
+
+// These are valid drift policies.
+const (
+	// DriftPolicyIgnore does not detect drift.
+	DriftPolicyIgnore DriftPolicy = "Ignore"
+	// DriftPolicyReport reports drift in the Drifted condition.
+	DriftPolicyReport DriftPolicy = "Report"
+	// DriftPolicyCorrect reports drift and re-applies the desired state.
+	DriftPolicyCorrect DriftPolicy = "Correct"
+)
+
+func (x DriftPolicy) String() string { return string(x) }
+
+// HelmValuesStrategy represents how the values of a Helm release are handled
+// on upgrade.
+type HelmValuesStrategy string
+
+// These are valid Helm values strategies.
+const (
+	// HelmValuesStrategyReuse merges the values over those of the last release.
+	HelmValuesStrategyReuse HelmValuesStrategy = "Reuse"
+	// HelmValuesStrategyReset replaces the values of the last release.
+	HelmValuesStrategyReset HelmValuesStrategy = "Reset"
+)
+
+func (x HelmValuesStrategy) String() string { return string(x) }
+
+// HelmCRDsPolicy represents how the CRDs of a Helm chart (i.e. the files in
+// its crds/ directory) are handled.
+type HelmCRDsPolicy string
+
+// These are valid Helm CRDs policies.
+const (
+	// HelmCRDsPolicySkip does not create or upgrade CRDs.
+	HelmCRDsPolicySkip HelmCRDsPolicy = "Skip"
+	// HelmCRDsPolicyCreate creates missing CRDs, but does not upgrade existing ones.
+	HelmCRDsPolicyCreate HelmCRDsPolicy = "Create"
+	// HelmCRDsPolicyUpgrade creates missing CRDs and upgrades existing ones.
+	HelmCRDsPolicyUpgrade HelmCRDsPolicy = "Upgrade"
+)
+
+func (x HelmCRDsPolicy) String() string { return string(x) }
+
+// HelmOptions configures the Helm actions run for an OceanComponent of type Helm.
+type HelmOptions struct {
+	// Wait waits until all resources of the release are ready before marking
+	// it successful, for as long as Timeout.
+	// +optional
+	Wait bool `json:"wait,omitempty"`
+	// WaitForJobs waits until all Jobs of the release have completed before
+	// marking it successful, when Wait is set.
+	// +optional
+	WaitForJobs bool `json:"waitForJobs,omitempty"`
+	// Timeout is the time to wait for each Kubernetes operation, such as
+	// Jobs for hooks (e.g. "5m"). Defaults to "5m".
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Atomic uninstalls a failed installation, and rolls back a failed
+	// upgrade. It implies Wait.
+	// +optional
+	Atomic bool `json:"atomic,omitempty"`
+	// MaxHistory is the maximum number of revisions saved per release.
+	// Defaults to 0, meaning no limit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxHistory int `json:"maxHistory,omitempty"`
+	// DisableHooks prevents the hooks of the chart from running.
+	// +optional
+	DisableHooks bool `json:"disableHooks,omitempty"`
+	// ValuesStrategy determines how values are handled on upgrade, one of
+	// ["Reuse", "Reset"]. Defaults to "Reset", so that keys removed from the
+	// values are removed from the release.
+	// +kubebuilder:validation:Enum=Reuse;Reset
+	// +optional
+	ValuesStrategy HelmValuesStrategy `json:"valuesStrategy,omitempty"`
+	// CRDs determines how the CRDs of the chart are handled on install and
+	// upgrade, one of ["Skip", "Create", "Upgrade"]. Defaults to "Create".
+	// +kubebuilder:validation:Enum=Skip;Create;Upgrade
+	// +optional
+	CRDs HelmCRDsPolicy `json:"crds,omitempty"`
+}
+
+// RepositoryOptions configures access to the repository of an OceanComponent.
+type RepositoryOptions struct {
+	// SecretRef references a Secret, in the namespace of the OceanComponent,
+	// holding the credentials of the repository. Recognized keys are
+	// "username" and "password" for basic auth, "tls.crt" and "tls.key" for
+	// a client certificate, and "ca.crt" for a CA bundle. OCI registries are
+	// logged into using the docker config in ".dockerconfigjson".
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+	// InsecureSkipTLSVerify skips verifying the TLS certificate of the
+	// repository.
+	// +optional
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+	// PassCredentialsAll passes the credentials to all domains, rather than
+	// only to the domain of the repository.
+	// +optional
+	PassCredentialsAll bool `json:"passCredentialsAll,omitempty"`
+}
+
+// ChartSource locates a chart archive available without network access.
+// Exactly one of its fields must be set.
+type ChartSource struct {
+	// ConfigMap selects a key, in "binaryData" or "data", of a ConfigMap in
+	// the namespace of the OceanComponent holding a chart archive (.tgz).
+	// +optional
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+	// Secret selects a key of a Secret in the namespace of the OceanComponent
+	// holding a chart archive (.tgz).
+	// +optional
+	Secret *corev1.SecretKeySelector `json:"secret,omitempty"`
+	// Path is the location of a chart archive or directory in the file
+	// system of the operator, either an absolute path or a "file://" URL.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// ChartVerification configures the verification of the provenance of a chart,
+// as done by "helm verify".
+type ChartVerification struct {
+	// KeyringSecretRef selects a key of a Secret, in the namespace of the
+	// OceanComponent, holding the public keyring the chart must be signed
+	// with.
+	KeyringSecretRef corev1.SecretKeySelector `json:"keyringSecretRef"`
+}
+
+// ManifestSource locates the manifest of an OceanComponent of type Manifest,
+// a multi-document YAML file of Kubernetes objects. Exactly one of its fields
+// must be set.
+type ManifestSource struct {
+	// URL is the HTTP(S) location of the manifest.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// ConfigMap selects a key of a ConfigMap in the namespace of the
+	// OceanComponent holding the manifest.
+	// +optional
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+	// Inline is the manifest itself.
+	// +optional
+	Inline string `json:"inline,omitempty"`
+}
+
+// KustomizeSource locates the kustomization of an OceanComponent of type
+// Kustomize. Exactly one of Path, ConfigMap and URL must be set.
+type KustomizeSource struct {
+	// Path is the location of a kustomization directory in the file system
+	// of the operator, either an absolute path or a "file://" URL.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// ConfigMap references a ConfigMap, in the namespace of the
+	// OceanComponent, whose keys are the files of a kustomization directory.
+	// +optional
+	ConfigMap *corev1.LocalObjectReference `json:"configMap,omitempty"`
+	// URL is the HTTP(S) location of a gzipped tar archive (.tar.gz) holding
+	// a kustomization directory.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// Dir is the relative path of the kustomization directory within the
+	// archive at URL. Defaults to its root.
+	// +optional
+	Dir string `json:"dir,omitempty"`
+}
+
+// PluginOptions selects the out-of-process installer plugin of an
+// OceanComponent of type Plugin.
+type PluginOptions struct {
+	// Name is the name of a plugin discovered by the operator.
+	Name string `json:"name"`
+	// Config is passed as is to the plugin, which defines its schema.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Config *apiextensionsv1.JSON `json:"config,omitempty"`
+}
+
+// PatchTarget selects the rendered objects a patch is applied to.
+type PatchTarget struct {
+	// Group of the patched objects. Defaults to the core group.
+	// +optional
+	Group string `json:"group,omitempty"`
+	// Version of the patched objects. Defaults to any version.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Kind of the patched objects.
+	Kind string `json:"kind"`
+	// Name of the patched objects.
+	Name string `json:"name"`
+	// Namespace of the patched objects. Defaults to any namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// JSON6902Patch is a JSON patch, as defined by RFC 6902, of rendered objects.
+type JSON6902Patch struct {
+	// Target selects the patched objects.
+	Target PatchTarget `json:"target"`
+	// Patch is the list of patch operations, in YAML or JSON (e.g. "- op:
+	// add\n  path: /spec/template/spec/tolerations/-\n  value: ...").
+	Patch string `json:"patch"`
+}
+
+// PostRender describes changes made to the rendered manifest of an
+// OceanComponent before it is applied, in the manner of a kustomization.
+type PostRender struct {
+	// CommonLabels are added to the metadata of all rendered objects.
+	// +optional
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+	// CommonAnnotations are added to the metadata of all rendered objects.
+	// +optional
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
+	// PatchesStrategicMerge is a list of strategic merge patches, each
+	// applied to the rendered object with the same apiVersion, kind, name
+	// and, if set, namespace. Objects of kinds unknown to the operator, such
+	// as custom resources, are patched with JSON merge patches.
+	// +optional
+	PatchesStrategicMerge []apiextensionsv1.JSON `json:"patchesStrategicMerge,omitempty"`
+	// PatchesJSON6902 is a list of JSON patches, each applied to the
+	// rendered objects selected by its target.
+	// +optional
+	PatchesJSON6902 []JSON6902Patch `json:"patchesJson6902,omitempty"`
+}
+
+// OceanComponentSpec defines the desired state of OceanComponent.
+type OceanComponentSpec struct {
+	// Type is one of ["Helm", "Manifest", "Kustomize", "Plugin"]. Defaulted
+	// for components known to the catalog.
+	// +optional
+	Type OceanComponentType `json:"type,omitempty"`
+	// Name is the name of the OceanComponent.
+	Name OceanComponentName `json:"name"`
+	// Chart is the name of the chart, in the repository at URL, of the
+	// OceanComponent. Defaults to Name.
+	// +optional
+	Chart string `json:"chart,omitempty"`
+	// ReleaseName is the name of the release of the OceanComponent, which
+	// must be unique within TargetNamespace. Defaults to Name. Immutable.
+	// +optional
+	ReleaseName string `json:"releaseName,omitempty"`
+	// TargetNamespace is the namespace the OceanComponent is installed into.
+	// Defaults to the namespace of the operator. Immutable.
+	// +optional
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+	// State determines whether the component should be installed or removed.
+	// Defaults to "Present".
+	// +optional
+	State OceanComponentState `json:"state,omitempty"`
+	// URL is the location of the OceanComponent archive file, either a chart
+	// repository or an OCI registry (e.g. "oci://registry.example.com/charts").
+	// Defaulted for components known to the catalog.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// Manifest locates the manifest of the OceanComponent, in place of URL,
+	// when its type is "Manifest".
+	// +optional
+	Manifest *ManifestSource `json:"manifest,omitempty"`
+	// Kustomize locates the kustomization of the OceanComponent, in place of
+	// URL, when its type is "Kustomize".
+	// +optional
+	Kustomize *KustomizeSource `json:"kustomize,omitempty"`
+	// Plugin selects the installer plugin the OceanComponent is delegated
+	// to, when its type is "Plugin". URL and Version are passed to the
+	// plugin, which interprets them.
+	// +optional
+	Plugin *PluginOptions `json:"plugin,omitempty"`
+	// Source locates the chart of the OceanComponent without network access,
+	// in place of URL, for clusters without egress. The version of the chart
+	// must satisfy Version, if set.
+	// +optional
+	Source *ChartSource `json:"source,omitempty"`
+	// Verify configures the verification of the provenance (.prov) file of
+	// the chart downloaded from URL. Unverified charts are not installed.
+	// +optional
+	Verify *ChartVerification `json:"verify,omitempty"`
+	// Digest pins the SHA-256 digest of the chart archive (e.g.
+	// "sha256:1a2b..."). Charts with a different digest are not installed.
+	// +kubebuilder:validation:Pattern=`^(sha256:)?[a-f0-9]{64}$`
+	// +optional
+	Digest string `json:"digest,omitempty"`
+	// Repository configures access to the repository at URL, which is
+	// anonymous by default.
+	// +optional
+	Repository *RepositoryOptions `json:"repository,omitempty"`
+	// Version is a SemVer 2 conformant version string, or version constraint
+	// (e.g. "~1.0" or ">=1.0.90 <2"), of the OceanComponent archive file.
+	// Constraints, and the empty string, are resolved to the latest matching
+	// version. Defaulted for components known to the catalog, unless URL
+	// differs from the catalog's. Components of type Manifest and Kustomize
+	// are not versioned, and may only record an exact version.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Values is the set of extra values added to the OceanComponent.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Values *apiextensionsv1.JSON `json:"values,omitempty"`
+	// ValuesFrom is a list of references to ConfigMaps and Secrets holding
+	// values for the OceanComponent. References are merged in the order they
+	// are listed, and Values is merged last, so it takes precedence.
+	// +optional
+	ValuesFrom []ValuesReference `json:"valuesFrom,omitempty"`
+	// DependsOn is a list of names of OceanComponents, in the same namespace,
+	// that must be available before the component is installed. Components
+	// are removed in the reverse order.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// HealthChecks is a list of checks used to determine whether the
+	// component is available or degraded. When empty, the Deployments,
+	// DaemonSets and StatefulSets of the installed release are checked, and
+	// components without any are available as soon as they are installed.
+	// +optional
+	HealthChecks []HealthCheck `json:"healthChecks,omitempty"`
+	// UpgradePolicy determines which version changes are applied
+	// automatically when Version is a constraint, one of ["Manual", "Patch",
+	// "Minor"]. Manual holds back all of them, while Patch and Minor hold
+	// back changes beyond newer patch and minor versions of the installed
+	// version. Exact versions are always applied. When empty, all version
+	// changes are applied.
+	// +kubebuilder:validation:Enum=Manual;Patch;Minor
+	// +optional
+	UpgradePolicy UpgradePolicy `json:"upgradePolicy,omitempty"`
+	// MaintenanceWindows restrict upgrades to the given time ranges. Pending
+	// upgrades are held back outside of them. When empty, upgrades run at any
+	// time. Installations are not restricted.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// Suspend tells the operator to suspend the reconciliation of the
+	// component. While suspended, the component is neither installed,
+	// upgraded nor removed, including when it is deleted, but its health is
+	// still reported. Defaults to false.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// DriftPolicy determines how drift of the objects created by the
+	// component from their rendered manifest is handled, one of ["Ignore",
+	// "Report", "Correct"]. Defaults to "Ignore".
+	// +kubebuilder:validation:Enum=Ignore;Report;Correct
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+	// Helm configures the Helm actions run for the component, when its type
+	// is "Helm".
+	// +optional
+	Helm *HelmOptions `json:"helm,omitempty"`
+	// PostRender describes changes made to the rendered manifest of the
+	// component on install and upgrade, such as patches of the objects
+	// of its chart that its values do not expose.
+	// +optional
+	PostRender *PostRender `json:"postRender,omitempty"`
+}
+
+// RollbackStatus describes the rollback of a failed OceanComponent release.
+type RollbackStatus struct {
+	// Time is the time of the rollback.
+	Time metav1.Time `json:"time"`
+	// FromRevision is the revision of the failed release.
+	FromRevision int `json:"fromRevision"`
+	// ToRevision is the revision rolled back to.
+	ToRevision int `json:"toRevision"`
+	// FailedVersion is the version of the failed release.
+	// +optional
+	FailedVersion string `json:"failedVersion,omitempty"`
+	// FailedValuesHash is the SHA-256 hash of the desired values of the
+	// failed release.
+	// +optional
+	FailedValuesHash string `json:"failedValuesHash,omitempty"`
+	// ObservedGeneration is the generation of the component when it was
+	// rolled back. The failed version and values are not retried until the
+	// generation changes.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// OceanComponentStatus defines the observed state of OceanComponent.
+type OceanComponentStatus struct {
+	// A set of installation values specific to the component
+	Properties map[string]string         `json:"properties,omitempty"`
+	Conditions []OceanComponentCondition `json:"conditions,omitempty"`
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Version is the version of the installed OceanComponent archive file.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// AppVersion is the version of the application enclosed inside the
+	// installed OceanComponent archive file.
+	// +optional
+	AppVersion string `json:"appVersion,omitempty"`
+	// ResolvedVersion is the version that Spec.Version resolved to during the
+	// last reconciliation.
+	// +optional
+	ResolvedVersion string `json:"resolvedVersion,omitempty"`
+	// Revision is the revision of the installed release (e.g. Helm revision).
+	// +optional
+	Revision int `json:"revision,omitempty"`
+	// ValuesHash is the SHA-256 hash of the effective values of the last
+	// install or upgrade.
+	// +optional
+	ValuesHash string `json:"valuesHash,omitempty"`
+	// LastOperationTime is the time of the last install, upgrade, rollback
+	// or uninstall.
+	// +optional
+	LastOperationTime *metav1.Time `json:"lastOperationTime,omitempty"`
+	// LastRollback describes the last rollback of a failed release.
+	// +optional
+	LastRollback *RollbackStatus `json:"lastRollback,omitempty"`
+}
+
+// ChartName returns the name of the chart of the component.
+func (r *OceanComponent) ChartName() string {
+	if r.Spec.Chart != "" {
+		return r.Spec.Chart
+	}
+	return r.Spec.Name.String()
+}
+
+// ReleaseName returns the name of the release of the component.
+func (r *OceanComponent) ReleaseName() string {
+	if r.Spec.ReleaseName != "" {
+		return r.Spec.ReleaseName
+	}
+	return r.Spec.Name.String()
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=oc,path=oceancomponents
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Component",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.spec.state`
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.version`
+// +kubebuilder:printcolumn:name="App Version",type=string,JSONPath=`.status.appVersion`,priority=1
+// +kubebuilder:printcolumn:name="Revision",type=integer,JSONPath=`.status.revision`,priority=1
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// OceanComponent is the Schema for the OceanComponent API
+type OceanComponent struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OceanComponentSpec   `json:"spec,omitempty"`
+	Status OceanComponentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OceanComponentList contains a list of OceanComponent
+type OceanComponentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OceanComponent `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OceanComponent{}, &OceanComponentList{})
+}
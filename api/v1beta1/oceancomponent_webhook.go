@@ -0,0 +1,565 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/yaml"
+)
+
+// maxReleaseNameLength is the maximum length of a release name.
+const maxReleaseNameLength = 53
+
+// digestRegexp matches SHA-256 digests of chart archives.
+var digestRegexp = regexp.MustCompile(`^(sha256:)?[a-f0-9]{64}$`)
+
+// SetupWebhookWithManager sets up the webhooks with the Manager.
+func (r *OceanComponent) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-ocean-spot-io-v1beta1-oceancomponent,mutating=true,failurePolicy=fail,sideEffects=None,groups=ocean.spot.io,resources=oceancomponents,verbs=create;update,versions=v1beta1,name=moceancomponent.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &OceanComponent{}
+
+// Default implements webhook.Defaulter. Components known to the catalog are
+// given the catalog's type, chart, URL, version and health checks, unless set
+// or sourced without network access, and the catalog's values fill the keys
+// missing from their values. Fields that are set, including the name, are
+// never overwritten.
+//
+// The catalog's dependencies are not defaulted. They are set on the components
+// of an OceanEnvironment, which are created together, whereas a component
+// created on its own would wait for dependencies that may never exist.
+func (r *OceanComponent) Default() {
+	if r.Spec.State == "" {
+		r.Spec.State = OceanComponentStatePresent
+	}
+
+	entry, ok := LookupCatalog(r.Spec.Name)
+	if !ok {
+		return
+	}
+	if r.Spec.Type == "" {
+		r.Spec.Type = entry.Spec.Type
+	}
+	// components named after their resource install the catalog's chart
+	if r.Spec.Chart == "" && r.Spec.Name != entry.Spec.Name {
+		r.Spec.Chart = entry.Spec.Name.String()
+	}
+	if r.Spec.URL == "" && r.Spec.Source == nil {
+		r.Spec.URL = entry.Spec.URL
+	}
+	// the catalog's version is only meaningful in the catalog's repository
+	if r.Spec.Version == "" && r.Spec.URL == entry.Spec.URL {
+		r.Spec.Version = entry.Spec.Version
+	}
+	if entry.Spec.Values != nil {
+		r.Spec.Values = mergeValues(entry.Spec.Values, r.Spec.Values)
+	}
+	if r.Spec.HealthChecks == nil {
+		r.Spec.HealthChecks = entry.Spec.HealthChecks
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-ocean-spot-io-v1beta1-oceancomponent,mutating=false,failurePolicy=fail,sideEffects=None,groups=ocean.spot.io,resources=oceancomponents,verbs=create;update,versions=v1beta1,name=voceancomponent.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &OceanComponent{}
+
+// ValidateCreate implements webhook.Validator.
+func (r *OceanComponent) ValidateCreate() error {
+	return r.toInvalidError(r.validateSpec())
+}
+
+// ValidateUpdate implements webhook.Validator.
+func (r *OceanComponent) ValidateUpdate(old runtime.Object) error {
+	allErrs := r.validateSpec()
+	if oldComp, ok := old.(*OceanComponent); ok {
+		specPath := field.NewPath("spec")
+		if oldComp.Spec.Name != r.Spec.Name {
+			allErrs = append(allErrs, field.Invalid(
+				specPath.Child("name"), r.Spec.Name, "field is immutable"))
+		}
+		if oldComp.Spec.ReleaseName != r.Spec.ReleaseName {
+			allErrs = append(allErrs, field.Invalid(
+				specPath.Child("releaseName"), r.Spec.ReleaseName, "field is immutable"))
+		}
+		if oldComp.Spec.TargetNamespace != r.Spec.TargetNamespace {
+			allErrs = append(allErrs, field.Invalid(
+				specPath.Child("targetNamespace"), r.Spec.TargetNamespace, "field is immutable"))
+		}
+	}
+	return r.toInvalidError(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator.
+func (r *OceanComponent) ValidateDelete() error {
+	return nil
+}
+
+func (r *OceanComponent) validateSpec() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	switch r.Spec.Type {
+	case OceanComponentTypeHelm, OceanComponentTypeManifest, OceanComponentTypeKustomize,
+		OceanComponentTypePlugin:
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("type"),
+			r.Spec.Type, []string{OceanComponentTypeHelm.String(),
+				OceanComponentTypeManifest.String(), OceanComponentTypeKustomize.String(),
+				OceanComponentTypePlugin.String()}))
+	}
+
+	switch r.Spec.State {
+	case OceanComponentStatePresent, OceanComponentStateAbsent:
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("state"),
+			r.Spec.State, []string{OceanComponentStatePresent.String(), OceanComponentStateAbsent.String()}))
+	}
+
+	if r.Spec.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("name"), ""))
+	}
+
+	// release names are limited by Helm, which stores them in labels
+	if name := r.Spec.ReleaseName; name != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(name) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("releaseName"), name, msg))
+		}
+		if len(name) > maxReleaseNameLength {
+			allErrs = append(allErrs, field.TooLong(specPath.Child("releaseName"), name, maxReleaseNameLength))
+		}
+	}
+
+	if ns := r.Spec.TargetNamespace; ns != "" {
+		for _, msg := range validation.IsDNS1123Label(ns) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("targetNamespace"), ns, msg))
+		}
+	}
+
+	if r.Spec.Type == OceanComponentTypeManifest {
+		if r.Spec.Manifest == nil {
+			allErrs = append(allErrs, field.Required(specPath.Child("manifest"), ""))
+		} else {
+			allErrs = append(allErrs, validateManifestSource(r.Spec.Manifest, specPath.Child("manifest"))...)
+		}
+	} else if r.Spec.Manifest != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("manifest"),
+			"may only be set for components of type Manifest"))
+	}
+	if r.Spec.Type == OceanComponentTypeKustomize {
+		if r.Spec.Kustomize == nil {
+			allErrs = append(allErrs, field.Required(specPath.Child("kustomize"), ""))
+		} else {
+			allErrs = append(allErrs, validateKustomizeSource(r.Spec.Kustomize, specPath.Child("kustomize"))...)
+		}
+	} else if r.Spec.Kustomize != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("kustomize"),
+			"may only be set for components of type Kustomize"))
+	}
+	if r.Spec.Type == OceanComponentTypePlugin {
+		if r.Spec.Plugin == nil || r.Spec.Plugin.Name == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("plugin", "name"), ""))
+		} else {
+			for _, msg := range validation.IsDNS1123Label(r.Spec.Plugin.Name) {
+				allErrs = append(allErrs, field.Invalid(specPath.Child("plugin", "name"), r.Spec.Plugin.Name, msg))
+			}
+		}
+	} else if r.Spec.Plugin != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("plugin"),
+			"may only be set for components of type Plugin"))
+	}
+
+	switch {
+	case r.Spec.Type == OceanComponentTypeManifest || r.Spec.Type == OceanComponentTypeKustomize:
+		// manifests are not resolved from version constraints
+		if r.Spec.Version != "" {
+			if _, err := semver.StrictNewVersion(strings.TrimPrefix(r.Spec.Version, "v")); err != nil {
+				allErrs = append(allErrs, field.Invalid(specPath.Child("version"), r.Spec.Version,
+					fmt.Sprintf("must be an exact version for components of type %s", r.Spec.Type)))
+			}
+		}
+	case r.Spec.Type == OceanComponentTypePlugin:
+		// the URL, if any, is interpreted by the plugin
+	case r.Spec.Source != nil:
+		allErrs = append(allErrs, validateChartSource(r.Spec.Source, specPath.Child("source"))...)
+	case r.Spec.URL == "":
+		allErrs = append(allErrs, field.Required(specPath.Child("url"), ""))
+	default:
+		if u, err := url.Parse(r.Spec.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("url"),
+				r.Spec.URL, "must be an absolute URL"))
+		}
+	}
+
+	if repo := r.Spec.Repository; repo != nil && repo.SecretRef != nil && repo.SecretRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("repository", "secretRef", "name"), ""))
+	}
+
+	if v := r.Spec.Verify; v != nil {
+		verifyPath := specPath.Child("verify")
+		if v.KeyringSecretRef.Name == "" || v.KeyringSecretRef.Key == "" {
+			allErrs = append(allErrs, field.Required(verifyPath.Child("keyringSecretRef"),
+				"name and key are required"))
+		}
+		if r.Spec.Source != nil {
+			allErrs = append(allErrs, field.Forbidden(verifyPath,
+				"may not be set for charts with a source, which have no provenance"))
+		}
+	}
+
+	if r.Spec.Digest != "" && !digestRegexp.MatchString(r.Spec.Digest) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("digest"),
+			r.Spec.Digest, "must be a SHA-256 digest, optionally prefixed by \"sha256:\""))
+	}
+
+	// an empty version indicates the latest version
+	if r.Spec.Version != "" {
+		if _, err := semver.NewConstraint(r.Spec.Version); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("version"),
+				r.Spec.Version, "must be a SemVer 2 conformant version or version constraint"))
+		}
+	}
+
+	if r.Spec.Values != nil && len(r.Spec.Values.Raw) > 0 {
+		var v map[string]interface{}
+		if err := json.Unmarshal(r.Spec.Values.Raw, &v); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("values"),
+				string(r.Spec.Values.Raw), "must be an object"))
+		}
+	}
+
+	for i, dep := range r.Spec.DependsOn {
+		if dep == r.Name {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("dependsOn").Index(i),
+				dep, "must not depend on itself"))
+		}
+	}
+
+	for i, check := range r.Spec.HealthChecks {
+		checkPath := specPath.Child("healthChecks").Index(i)
+		if (check.Name == "") == (check.Selector == nil) {
+			allErrs = append(allErrs, field.Invalid(checkPath,
+				check.Name, "exactly one of name and selector must be set"))
+		}
+		if check.Selector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(check.Selector); err != nil {
+				allErrs = append(allErrs, field.Invalid(checkPath.Child("selector"),
+					check.Selector, err.Error()))
+			}
+		}
+		if check.MinAvailable != nil && *check.MinAvailable < 0 {
+			allErrs = append(allErrs, field.Invalid(checkPath.Child("minAvailable"),
+				*check.MinAvailable, "must be greater than or equal to 0"))
+		}
+	}
+
+	switch r.Spec.UpgradePolicy {
+	case "", UpgradePolicyManual, UpgradePolicyPatch, UpgradePolicyMinor:
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("upgradePolicy"),
+			r.Spec.UpgradePolicy, []string{UpgradePolicyManual.String(),
+				UpgradePolicyPatch.String(), UpgradePolicyMinor.String()}))
+	}
+
+	switch r.Spec.DriftPolicy {
+	case "", DriftPolicyIgnore, DriftPolicyReport, DriftPolicyCorrect:
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("driftPolicy"),
+			r.Spec.DriftPolicy, []string{DriftPolicyIgnore.String(),
+				DriftPolicyReport.String(), DriftPolicyCorrect.String()}))
+	}
+
+	if opts := r.Spec.Helm; opts != nil {
+		helmPath := specPath.Child("helm")
+		if r.Spec.Type != OceanComponentTypeHelm {
+			allErrs = append(allErrs, field.Forbidden(helmPath,
+				"may only be set for components of type Helm"))
+		}
+		if opts.Timeout != nil && opts.Timeout.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(helmPath.Child("timeout"),
+				opts.Timeout.Duration.String(), "must be greater than 0"))
+		}
+		if opts.MaxHistory < 0 {
+			allErrs = append(allErrs, field.Invalid(helmPath.Child("maxHistory"),
+				opts.MaxHistory, "must be greater than or equal to 0"))
+		}
+		switch opts.ValuesStrategy {
+		case "", HelmValuesStrategyReuse, HelmValuesStrategyReset:
+		default:
+			allErrs = append(allErrs, field.NotSupported(helmPath.Child("valuesStrategy"),
+				opts.ValuesStrategy, []string{HelmValuesStrategyReuse.String(), HelmValuesStrategyReset.String()}))
+		}
+		switch opts.CRDs {
+		case "", HelmCRDsPolicySkip, HelmCRDsPolicyCreate, HelmCRDsPolicyUpgrade:
+		default:
+			allErrs = append(allErrs, field.NotSupported(helmPath.Child("crds"),
+				opts.CRDs, []string{HelmCRDsPolicySkip.String(),
+					HelmCRDsPolicyCreate.String(), HelmCRDsPolicyUpgrade.String()}))
+		}
+	}
+
+	if pr := r.Spec.PostRender; pr != nil {
+		if r.Spec.Type != OceanComponentTypeHelm {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("postRender"),
+				"may only be set for components of type Helm"))
+		}
+		allErrs = append(allErrs, validatePostRender(pr, specPath.Child("postRender"))...)
+	}
+
+	for i, window := range r.Spec.MaintenanceWindows {
+		windowPath := specPath.Child("maintenanceWindows").Index(i)
+		for j, day := range window.Days {
+			if !isWeekday(day) {
+				allErrs = append(allErrs, field.Invalid(windowPath.Child("days").Index(j),
+					day, "must be a day of the week"))
+			}
+		}
+		if _, err := time.Parse(TimeOfDayLayout, window.Start); err != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("start"),
+				window.Start, "must be a time of day in HH:MM format"))
+		}
+		if _, err := time.Parse(TimeOfDayLayout, window.End); err != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("end"),
+				window.End, "must be a time of day in HH:MM format"))
+		}
+		if _, err := time.LoadLocation(window.TimeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("timeZone"),
+				window.TimeZone, "must be an IANA time zone name"))
+		}
+	}
+
+	return allErrs
+}
+
+// isWeekday reports whether the given day is a valid day of the week.
+func isWeekday(day Weekday) bool {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if d.String() == day.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// validateChartSource validates the given chart source.
+func validateChartSource(src *ChartSource, srcPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	set := 0
+	if src.ConfigMap != nil {
+		set++
+		if src.ConfigMap.Name == "" || src.ConfigMap.Key == "" {
+			allErrs = append(allErrs, field.Required(srcPath.Child("configMap"), "name and key are required"))
+		}
+	}
+	if src.Secret != nil {
+		set++
+		if src.Secret.Name == "" || src.Secret.Key == "" {
+			allErrs = append(allErrs, field.Required(srcPath.Child("secret"), "name and key are required"))
+		}
+	}
+	if src.Path != "" {
+		set++
+		if !path.IsAbs(strings.TrimPrefix(src.Path, "file://")) {
+			allErrs = append(allErrs, field.Invalid(srcPath.Child("path"),
+				src.Path, "must be an absolute path or file:// URL"))
+		}
+	}
+	if set != 1 {
+		allErrs = append(allErrs, field.Invalid(srcPath, "",
+			"exactly one of configMap, secret and path must be set"))
+	}
+	return allErrs
+}
+
+// validateManifestSource validates the given manifest source.
+func validateManifestSource(src *ManifestSource, srcPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	set := 0
+	if src.URL != "" {
+		set++
+		if u, err := url.Parse(src.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(srcPath.Child("url"),
+				src.URL, "must be an absolute HTTP(S) URL"))
+		}
+	}
+	if src.ConfigMap != nil {
+		set++
+		if src.ConfigMap.Name == "" || src.ConfigMap.Key == "" {
+			allErrs = append(allErrs, field.Required(srcPath.Child("configMap"), "name and key are required"))
+		}
+	}
+	if src.Inline != "" {
+		set++
+	}
+	if set != 1 {
+		allErrs = append(allErrs, field.Invalid(srcPath, "",
+			"exactly one of url, configMap and inline must be set"))
+	}
+	return allErrs
+}
+
+// validateKustomizeSource validates the given kustomize source.
+func validateKustomizeSource(src *KustomizeSource, srcPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	set := 0
+	if src.Path != "" {
+		set++
+		if !path.IsAbs(strings.TrimPrefix(src.Path, "file://")) {
+			allErrs = append(allErrs, field.Invalid(srcPath.Child("path"),
+				src.Path, "must be an absolute path or file:// URL"))
+		}
+	}
+	if src.ConfigMap != nil {
+		set++
+		if src.ConfigMap.Name == "" {
+			allErrs = append(allErrs, field.Required(srcPath.Child("configMap", "name"), ""))
+		}
+	}
+	if src.URL != "" {
+		set++
+		if u, err := url.Parse(src.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(srcPath.Child("url"),
+				src.URL, "must be an absolute HTTP(S) URL"))
+		}
+	}
+	if set != 1 {
+		allErrs = append(allErrs, field.Invalid(srcPath, "",
+			"exactly one of path, configMap and url must be set"))
+	}
+	if src.Dir != "" {
+		if src.URL == "" {
+			allErrs = append(allErrs, field.Forbidden(srcPath.Child("dir"), "may only be set with url"))
+		} else if path.IsAbs(src.Dir) || strings.HasPrefix(path.Clean(src.Dir), "..") {
+			allErrs = append(allErrs, field.Invalid(srcPath.Child("dir"),
+				src.Dir, "must be a relative path within the archive"))
+		}
+	}
+	return allErrs
+}
+
+// validatePostRender validates the given post-render configuration. Patches
+// are only checked for well-formedness, since they apply to rendered objects.
+func validatePostRender(pr *PostRender, prPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for k := range pr.CommonLabels {
+		for _, msg := range validation.IsQualifiedName(k) {
+			allErrs = append(allErrs, field.Invalid(prPath.Child("commonLabels"), k, msg))
+		}
+	}
+	for k, v := range pr.CommonLabels {
+		for _, msg := range validation.IsValidLabelValue(v) {
+			allErrs = append(allErrs, field.Invalid(prPath.Child("commonLabels").Key(k), v, msg))
+		}
+	}
+	for k := range pr.CommonAnnotations {
+		for _, msg := range validation.IsQualifiedName(strings.ToLower(k)) {
+			allErrs = append(allErrs, field.Invalid(prPath.Child("commonAnnotations"), k, msg))
+		}
+	}
+
+	for i, patch := range pr.PatchesStrategicMerge {
+		patchPath := prPath.Child("patchesStrategicMerge").Index(i)
+		var obj struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(patch.Raw, &obj); err != nil {
+			allErrs = append(allErrs, field.Invalid(patchPath, string(patch.Raw), "must be an object"))
+			continue
+		}
+		if obj.APIVersion == "" || obj.Kind == "" || obj.Metadata.Name == "" {
+			allErrs = append(allErrs, field.Required(patchPath,
+				"apiVersion, kind and metadata.name are required"))
+		}
+	}
+
+	for i, patch := range pr.PatchesJSON6902 {
+		patchPath := prPath.Child("patchesJson6902").Index(i)
+		if patch.Target.Kind == "" {
+			allErrs = append(allErrs, field.Required(patchPath.Child("target", "kind"), ""))
+		}
+		if patch.Target.Name == "" {
+			allErrs = append(allErrs, field.Required(patchPath.Child("target", "name"), ""))
+		}
+		var ops []map[string]interface{}
+		if err := yaml.Unmarshal([]byte(patch.Patch), &ops); err != nil || len(ops) == 0 {
+			allErrs = append(allErrs, field.Invalid(patchPath.Child("patch"),
+				patch.Patch, "must be a non-empty list of patch operations"))
+			continue
+		}
+		for j, op := range ops {
+			if op["op"] == nil || op["path"] == nil {
+				allErrs = append(allErrs, field.Required(patchPath.Child("patch").Index(j),
+					"op and path are required"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// mergeValues returns the given values merged over the base values. Values
+// that are not objects are returned unchanged, and left for validation.
+func mergeValues(base, values *apiextensionsv1.JSON) *apiextensionsv1.JSON {
+	var b, v map[string]interface{}
+	if err := json.Unmarshal(base.Raw, &b); err != nil {
+		return values
+	}
+	if values != nil && len(values.Raw) > 0 {
+		if err := json.Unmarshal(values.Raw, &v); err != nil {
+			return values
+		}
+	}
+	raw, err := json.Marshal(mergeMaps(b, v))
+	if err != nil {
+		return values
+	}
+	return &apiextensionsv1.JSON{Raw: raw}
+}
+
+// mergeMaps merges b over a, recursively.
+func mergeMaps(a, b map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		if bv, ok := v.(map[string]interface{}); ok {
+			if av, ok := out[k].(map[string]interface{}); ok {
+				out[k] = mergeMaps(av, bv)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func (r *OceanComponent) toInvalidError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("OceanComponent").GroupKind(), r.Name, allErrs)
+}
@@ -0,0 +1,277 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDefault(t *testing.T) {
+	t.Run("whenOnlyName", func(tt *testing.T) {
+		in := &OceanComponent{Spec: OceanComponentSpec{Name: MetricsServerComponentName}}
+		in.Default()
+		assert.Equal(tt, OceanComponentTypeHelm, in.Spec.Type)
+		assert.Equal(tt, OceanComponentStatePresent, in.Spec.State)
+		assert.Equal(tt, "https://charts.helm.sh/stable", in.Spec.URL)
+		assert.Equal(tt, "2.8.8", in.Spec.Version)
+		assert.NoError(tt, in.ValidateCreate())
+	})
+
+	t.Run("whenResourceName", func(tt *testing.T) {
+		in := &OceanComponent{Spec: OceanComponentSpec{
+			Name:   OceanControllerComponentName,
+			Values: &apiextensionsv1.JSON{Raw: []byte(`{"secret":{"enabled":false},"foo":"bar"}`)},
+		}}
+		in.Default()
+		assert.Equal(tt, OceanControllerComponentName, in.Spec.Name)
+		assert.Equal(tt, LegacyOceanControllerComponentName.String(), in.ChartName())
+		assert.JSONEq(tt, `{"secret":{"enabled":false},"metrics-server":{"deployChart":false},"foo":"bar"}`,
+			string(in.Spec.Values.Raw))
+	})
+
+	t.Run("whenURLSet", func(tt *testing.T) {
+		in := &OceanComponent{Spec: OceanComponentSpec{
+			Name: MetricsServerComponentName,
+			URL:  "https://example.com/charts",
+		}}
+		in.Default()
+		assert.Equal(tt, "https://example.com/charts", in.Spec.URL)
+		assert.Empty(tt, in.Spec.Version)
+	})
+
+	t.Run("whenSourceSet", func(tt *testing.T) {
+		in := &OceanComponent{Spec: OceanComponentSpec{
+			Name:   MetricsServerComponentName,
+			Source: &ChartSource{Path: "file:///charts/metrics-server-2.8.8.tgz"},
+		}}
+		in.Default()
+		assert.Empty(tt, in.Spec.URL)
+		assert.Empty(tt, in.Spec.Version)
+		assert.NoError(tt, in.ValidateCreate())
+	})
+
+	t.Run("whenChartSet", func(tt *testing.T) {
+		in := &OceanComponent{Spec: OceanComponentSpec{
+			Name:  OceanControllerComponentName,
+			Chart: "ocean-kubernetes-controller",
+		}}
+		in.Default()
+		assert.Equal(tt, OceanControllerComponentName, in.Spec.Name)
+		assert.Equal(tt, "ocean-kubernetes-controller", in.Spec.Chart)
+		assert.Empty(tt, in.Spec.DependsOn)
+	})
+
+	t.Run("whenUnknown", func(tt *testing.T) {
+		in := &OceanComponent{Spec: OceanComponentSpec{Name: "foo"}}
+		in.Default()
+		assert.Equal(tt, OceanComponentStatePresent, in.Spec.State)
+		assert.Empty(tt, in.Spec.Type)
+		assert.Empty(tt, in.Spec.URL)
+	})
+}
+
+func TestValidateCreate(t *testing.T) {
+	valid := &OceanComponent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metrics-server",
+			Namespace: NamespaceSystem,
+		},
+		Spec: OceanComponentSpec{
+			Type:    OceanComponentTypeHelm,
+			Name:    MetricsServerComponentName,
+			State:   OceanComponentStatePresent,
+			URL:     "https://charts.helm.sh/stable",
+			Version: "2.8.8",
+			Values:  &apiextensionsv1.JSON{Raw: []byte(`{"args":["--kubelet-insecure-tls"]}`)},
+		},
+	}
+
+	t.Run("whenValid", func(tt *testing.T) {
+		assert.NoError(tt, valid.ValidateCreate())
+	})
+
+	t.Run("whenVersionEmpty", func(tt *testing.T) {
+		in := valid.DeepCopy()
+		in.Spec.Version = ""
+		assert.NoError(tt, in.ValidateCreate())
+	})
+
+	t.Run("whenVersionConstraint", func(tt *testing.T) {
+		in := valid.DeepCopy()
+		in.Spec.Version = ">=2.8.0 <3"
+		assert.NoError(tt, in.ValidateCreate())
+	})
+
+	t.Run("whenManifest", func(tt *testing.T) {
+		in := valid.DeepCopy()
+		in.Spec.Type = OceanComponentTypeManifest
+		in.Spec.URL = ""
+		in.Spec.Manifest = &ManifestSource{URL: "https://example.com/metrics-server.yaml"}
+		assert.NoError(tt, in.ValidateCreate())
+	})
+
+	tests := []struct {
+		name   string
+		mutate func(comp *OceanComponent)
+		field  string
+	}{
+		{
+			name:   "whenTypeUnknown",
+			mutate: func(comp *OceanComponent) { comp.Spec.Type = "Jsonnet" },
+			field:  "spec.type",
+		},
+		{
+			name:   "whenStateUnknown",
+			mutate: func(comp *OceanComponent) { comp.Spec.State = "Installed" },
+			field:  "spec.state",
+		},
+		{
+			name:   "whenVersionInvalid",
+			mutate: func(comp *OceanComponent) { comp.Spec.Version = "latest" },
+			field:  "spec.version",
+		},
+		{
+			name:   "whenManifestMissing",
+			mutate: func(comp *OceanComponent) { comp.Spec.Type = OceanComponentTypeManifest },
+			field:  "spec.manifest",
+		},
+		{
+			name: "whenManifestVersionConstraint",
+			mutate: func(comp *OceanComponent) {
+				comp.Spec.Type = OceanComponentTypeManifest
+				comp.Spec.Manifest = &ManifestSource{Inline: "apiVersion: v1\nkind: ConfigMap\n"}
+				comp.Spec.Version = "~2.8"
+			},
+			field: "spec.version",
+		},
+		{
+			name: "whenKustomizeDirEscapesArchive",
+			mutate: func(comp *OceanComponent) {
+				comp.Spec.Type = OceanComponentTypeKustomize
+				comp.Spec.Kustomize = &KustomizeSource{URL: "https://example.com/overlays.tar.gz", Dir: "../base"}
+			},
+			field: "spec.kustomize.dir",
+		},
+		{
+			name: "whenPluginMissing",
+			mutate: func(comp *OceanComponent) {
+				comp.Spec.Type = OceanComponentTypePlugin
+			},
+			field: "spec.plugin.name",
+		},
+		{
+			name:   "whenReleaseNameInvalid",
+			mutate: func(comp *OceanComponent) { comp.Spec.ReleaseName = "Metrics_Server" },
+			field:  "spec.releaseName",
+		},
+		{
+			name:   "whenURLRelative",
+			mutate: func(comp *OceanComponent) { comp.Spec.URL = "charts.helm.sh/stable" },
+			field:  "spec.url",
+		},
+		{
+			name: "whenRepositorySecretNameEmpty",
+			mutate: func(comp *OceanComponent) {
+				comp.Spec.Repository = &RepositoryOptions{SecretRef: &corev1.LocalObjectReference{}}
+			},
+			field: "spec.repository.secretRef.name",
+		},
+		{
+			name: "whenSourceAmbiguous",
+			mutate: func(comp *OceanComponent) {
+				comp.Spec.Source = &ChartSource{
+					Path:      "/charts/metrics-server.tgz",
+					ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "charts"}, Key: "chart.tgz"},
+				}
+			},
+			field: "spec.source",
+		},
+		{
+			name:   "whenDigestInvalid",
+			mutate: func(comp *OceanComponent) { comp.Spec.Digest = "md5:d41d8cd98f00b204e9800998ecf8427e" },
+			field:  "spec.digest",
+		},
+		{
+			name: "whenJSON6902PatchInvalid",
+			mutate: func(comp *OceanComponent) {
+				comp.Spec.PostRender = &PostRender{PatchesJSON6902: []JSON6902Patch{{
+					Target: PatchTarget{Group: "apps", Kind: "Deployment", Name: "foo"},
+					Patch:  "- path: /spec/replicas",
+				}}}
+			},
+			field: "spec.postRender.patchesJson6902[0].patch[0]",
+		},
+		{
+			name:   "whenUpgradePolicyUnknown",
+			mutate: func(comp *OceanComponent) { comp.Spec.UpgradePolicy = "Major" },
+			field:  "spec.upgradePolicy",
+		},
+		{
+			name: "whenMaintenanceWindowTimeZoneUnknown",
+			mutate: func(comp *OceanComponent) {
+				comp.Spec.MaintenanceWindows = []MaintenanceWindow{{Start: "01:00", End: "05:00", TimeZone: "Mars/Olympus"}}
+			},
+			field: "spec.maintenanceWindows[0].timeZone",
+		},
+		{
+			name: "whenHelmTimeoutNegative",
+			mutate: func(comp *OceanComponent) {
+				comp.Spec.Helm = &HelmOptions{Timeout: &metav1.Duration{Duration: -time.Second}}
+			},
+			field: "spec.helm.timeout",
+		},
+		{
+			name:   "whenValuesNotObject",
+			mutate: func(comp *OceanComponent) { comp.Spec.Values = &apiextensionsv1.JSON{Raw: []byte(`"foo"`)} },
+			field:  "spec.values",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			in := valid.DeepCopy()
+			test.mutate(in)
+			err := in.ValidateCreate()
+			assert.True(tt, apierrors.IsInvalid(err))
+			assert.Contains(tt, err.Error(), test.field)
+		})
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	old := &OceanComponent{
+		Spec: OceanComponentSpec{
+			Type:  OceanComponentTypeHelm,
+			Name:  MetricsServerComponentName,
+			State: OceanComponentStatePresent,
+			URL:   "https://charts.helm.sh/stable",
+		},
+	}
+
+	t.Run("whenVersionChanged", func(tt *testing.T) {
+		in := old.DeepCopy()
+		in.Spec.Version = "2.8.8"
+		assert.NoError(tt, in.ValidateUpdate(old))
+	})
+
+	t.Run("whenNameChanged", func(tt *testing.T) {
+		in := old.DeepCopy()
+		in.Spec.Name = OceanControllerComponentName
+		err := in.ValidateUpdate(old)
+		assert.True(tt, apierrors.IsInvalid(err))
+		assert.Contains(tt, err.Error(), "spec.name")
+	})
+	t.Run("whenTargetNamespaceChanged", func(tt *testing.T) {
+		in := old.DeepCopy()
+		in.Spec.TargetNamespace = "kube-system"
+		err := in.ValidateUpdate(old)
+		assert.True(tt, apierrors.IsInvalid(err))
+		assert.Contains(tt, err.Error(), "spec.targetNamespace")
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultOceanEnvironmentName is the name of the OceanEnvironment created
+// while bootstrapping the operator.
+const DefaultOceanEnvironmentName = "default"
+
+// OceanEnvironmentLabel is the label placed on every OceanComponent owned by
+// an OceanEnvironment, set to the name of the environment. Existing
+// OceanComponents are adopted by the environment only if they carry it.
+const OceanEnvironmentLabel = "ocean.spot.io/environment"
+
+// OceanEnvironmentComponent declares a component enabled in an OceanEnvironment
+// and, optionally, overrides of its built-in defaults.
+type OceanEnvironmentComponent struct {
+	// Name is the name of a built-in component (e.g. "metrics-server").
+	Name OceanComponentName `json:"name"`
+	// URL overrides the location of the OceanComponent archive file.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// Version overrides the version of the OceanComponent archive file.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Values are merged over the built-in values of the OceanComponent.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Values *apiextensionsv1.JSON `json:"values,omitempty"`
+	// ValuesFrom replaces the values references of the OceanComponent.
+	// +optional
+	ValuesFrom []ValuesReference `json:"valuesFrom,omitempty"`
+}
+
+// OceanEnvironmentSpec defines the desired state of OceanEnvironment.
+type OceanEnvironmentSpec struct {
+	// Namespace is the namespace where the OceanComponents of the environment
+	// are placed. Defaults to "spot-system".
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Components is the list of enabled components. Built-in components that
+	// are not listed are kept absent, if owned by the environment. The spec of
+	// the owned OceanComponents is managed by the environment, and changes
+	// made to it, other than suspension, are overwritten.
+	// +optional
+	Components []OceanEnvironmentComponent `json:"components,omitempty"`
+}
+
+// OceanEnvironmentComponentStatus describes the observed state of a
+// component owned by an OceanEnvironment.
+type OceanEnvironmentComponentStatus struct {
+	// Name is the name of the OceanComponent.
+	Name string `json:"name"`
+	// State is the desired state of the OceanComponent.
+	State OceanComponentState `json:"state"`
+	// Ready is the status of the Ready condition of the OceanComponent.
+	Ready corev1.ConditionStatus `json:"ready"`
+	// The reason of the Ready condition of the OceanComponent.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// OceanEnvironmentStatus defines the observed state of OceanEnvironment.
+type OceanEnvironmentStatus struct {
+	Conditions []OceanComponentCondition `json:"conditions,omitempty"`
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Components is the aggregated status of the owned OceanComponents.
+	// +optional
+	Components []OceanEnvironmentComponentStatus `json:"components,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=oe,path=oceanenvironments
+// +kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.spec.namespace`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// OceanEnvironment is the Schema for the OceanEnvironment API
+type OceanEnvironment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OceanEnvironmentSpec   `json:"spec,omitempty"`
+	Status OceanEnvironmentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OceanEnvironmentList contains a list of OceanEnvironment
+type OceanEnvironmentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OceanEnvironment `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OceanEnvironment{}, &OceanEnvironmentList{})
+}
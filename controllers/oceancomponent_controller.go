@@ -5,22 +5,32 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
-	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
 	ctrlutil "github.com/spotinst/ocean-operator/internal/controller"
 	"github.com/spotinst/ocean-operator/internal/version"
 	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/spotinst/ocean-operator/pkg/installer/cache"
 	_ "github.com/spotinst/ocean-operator/pkg/installer/installers"
+	"github.com/spotinst/ocean-operator/pkg/installer/installers/plugin"
 	"github.com/spotinst/ocean-operator/pkg/log"
 	"github.com/spotinst/ocean-operator/pkg/tide/values"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
@@ -31,35 +41,174 @@ const (
 	OperatorVersionAnnotation = "operator.ocean.spot.io/version"
 )
 
+// These are reasons of conditions set while ordering components by their
+// dependencies.
+const (
+	reasonDependencyCycle        = "DependencyCycle"
+	reasonDependenciesReady      = "DependenciesReady"
+	reasonWaitingForDependencies = "WaitingForDependencies"
+	reasonWaitingForDependents   = "WaitingForDependents"
+)
+
+// These are reasons of conditions set while suspending reconciliation.
+const (
+	reasonSuspended = "Suspended"
+	reasonResumed   = "Resumed"
+)
+
+// reasonVerificationFailed is the reason of the Failing condition set when the
+// archive of a component cannot be verified.
+const reasonVerificationFailed = "VerificationFailed"
+
+// reasonPluginNotFound is the reason of the Failing condition set when the
+// plugin of a component is not discovered.
+const reasonPluginNotFound = "PluginNotFound"
+
+// reasonPendingTimedOut is the reason of the Failing condition set when a
+// release stays pending for longer than the operation timeout.
+const reasonPendingTimedOut = "PendingTimedOut"
+
+// defaultPendingTimeout is the time after which a pending release is
+// considered stuck, when there is no operation timeout.
+const defaultPendingTimeout = 15 * time.Minute
+
+// reasonOperationTimedOut is the reason of the Failing condition set when an
+// installer operation exceeds the operation timeout.
+const reasonOperationTimedOut = "OperationTimedOut"
+
+// suspendedInterval is the interval at which the health of suspended
+// components is refreshed.
+const suspendedInterval = time.Minute
+
+// versionResolutionInterval is the interval at which components whose version
+// is a constraint are requeued, so newer versions satisfying it are installed.
+const versionResolutionInterval = 15 * time.Minute
+
+// availabilityInterval is the interval after an install or an upgrade at
+// which the availability of the component is checked.
+const availabilityInterval = 15 * time.Second
+
+// driftDetectionInterval is the interval at which components are checked for
+// drift, unless their drift policy is Ignore.
+const driftDetectionInterval = 5 * time.Minute
+
 // OceanComponentReconciler reconciles a OceanComponent object
 type OceanComponentReconciler struct {
 	Scheme       *runtime.Scheme
 	Client       client.Client
 	ClientGetter genericclioptions.RESTClientGetter
 	Log          log.Logger
+	Recorder     record.EventRecorder
+	Cache        *cache.Cache
 	Namespace    string
+	// OperationTimeout is the deadline of each installer operation, such as
+	// an install or an upgrade. Zero means no deadline.
+	OperationTimeout time.Duration
 }
 
 // Helm requires cluster-admin access, but here we'll explicitly mention a few
 // resources that the ocean operator accesses directly:
 //
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create
-// +kubebuilder:rbac:groups="apps",resources=deployments,verbs=get;list;watch;create;update;patch;uninstall
-// +kubebuilder:rbac:groups=ocean.spot.io,resources=components,verbs=get;list;watch;create;update;patch;uninstall
-// +kubebuilder:rbac:groups=ocean.spot.io,resources=components/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups=ocean.spot.io,resources=components/finalizers,verbs=update
+// +kubebuilder:rbac:groups="apps",resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="apps",resources=daemonsets;statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="batch",resources=jobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="apiregistration.k8s.io",resources=apiservices,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ocean.spot.io,resources=oceancomponents,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ocean.spot.io,resources=oceancomponents/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ocean.spot.io,resources=oceancomponents/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *OceanComponentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(),
+		&oceanv1beta1.OceanComponent{}, referencesIndexKey, indexReferences); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(),
+		&oceanv1beta1.OceanComponent{}, dependsOnIndexKey, indexDependsOn); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&oceanv1alpha1.OceanComponent{}).
+		For(&oceanv1beta1.OceanComponent{}).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.componentsReferencing(oceanv1beta1.ValuesReferenceKindConfigMap)),
+			builder.OnlyMetadata,
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.componentsReferencing(oceanv1beta1.ValuesReferenceKindSecret)),
+			builder.OnlyMetadata,
+		).
+		Watches(
+			&source.Kind{Type: &oceanv1beta1.OceanComponent{}},
+			handler.EnqueueRequestsFromMapFunc(r.componentsRelated),
+		).
 		Complete(r)
 }
 
+// componentsReferencing returns a handler.MapFunc that maps a ConfigMap or
+// Secret to requests for all components referencing it. The object may hold
+// its metadata only.
+func (r *OceanComponentReconciler) componentsReferencing(kind oceanv1beta1.ValuesReferenceKind) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		list := new(oceanv1beta1.OceanComponentList)
+		if err := r.Client.List(context.Background(), list,
+			client.InNamespace(obj.GetNamespace()),
+			client.MatchingFields{referencesIndexKey: referencesIndexValue(kind, obj.GetName())},
+		); err != nil {
+			r.Log.Error(err, "unable to list components referencing object",
+				"kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(list.Items))
+		for _, item := range list.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKeyFromObject(&item),
+			})
+		}
+		return requests
+	}
+}
+
+// componentsRelated is a handler.MapFunc that maps a component to requests for
+// its dependencies and dependents, which may be waiting for it.
+func (r *OceanComponentReconciler) componentsRelated(obj client.Object) []reconcile.Request {
+	comp, ok := obj.(*oceanv1beta1.OceanComponent)
+	if !ok {
+		return nil
+	}
+	list := new(oceanv1beta1.OceanComponentList)
+	if err := r.Client.List(context.Background(), list,
+		client.InNamespace(comp.Namespace),
+		client.MatchingFields{dependsOnIndexKey: comp.Name},
+	); err != nil {
+		r.Log.Error(err, "unable to list components depending on component",
+			"namespace", comp.Namespace, "name", comp.Name)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(list.Items)+len(comp.Spec.DependsOn))
+	for _, item := range list.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&item),
+		})
+	}
+	for _, name := range comp.Spec.DependsOn {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: comp.Namespace, Name: name},
+		})
+	}
+	return requests
+}
+
 type RequestContext struct {
 	ctrlutil.RequestContext
-	comp      *oceanv1alpha1.OceanComponent
+	comp      *oceanv1beta1.OceanComponent
 	installer installer.Installer
+	desired   *oceanv1beta1.OceanComponent
 	log       log.Logger
 }
 
@@ -73,7 +222,7 @@ func (r *OceanComponentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	rctx.log.Info("reconciling")
 
 	// get component by namespaced name
-	rctx.comp = new(oceanv1alpha1.OceanComponent)
+	rctx.comp = new(oceanv1beta1.OceanComponent)
 	if err := r.Client.Get(ctx, req.NamespacedName, rctx.comp); err != nil {
 		if !apierrors.IsNotFound(err) {
 			rctx.log.Error(err, "cannot retrieve")
@@ -81,7 +230,7 @@ func (r *OceanComponentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrlutil.NoRequeue()
 	}
 
-	// add finalizer and version annotation
+	// add finalizer and version annotation, and migrate legacy values
 	changed, err := r.setInitialValues(rctx.comp)
 	if err != nil {
 		return ctrlutil.RequeueError(err)
@@ -92,11 +241,36 @@ func (r *OceanComponentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	}
 
+	// apply the catalog's defaults in memory only, as the defaulting webhook
+	// may be disabled; the spec must not be written back from here on
+	rctx.comp.Default()
+
 	// initialize new installer
 	rctx.installer, err = r.newInstaller(rctx)
 	if err != nil {
 		rctx.log.Error(err, "cannot reconcile")
-		return r.unsupportedType(rctx)
+		return r.unsupportedType(rctx, err)
+	}
+
+	// suspended components are left alone, but their health is still reported
+	if suspended, source := isSuspended(rctx.comp); suspended {
+		return r.reconcileSuspended(rctx, source)
+	}
+	if err = r.resume(rctx); err != nil {
+		return ctrlutil.RequeueError(err)
+	}
+
+	// components are removed after their dependents, which trigger a
+	// reconciliation when removed; dependents that stay present never do, so
+	// the component is requeued with backoff until they are gone
+	if ctrlutil.IsBeingDeleted(rctx.comp) || rctx.comp.Spec.State == oceanv1beta1.OceanComponentStateAbsent {
+		removed, err := r.dependentsRemoved(rctx)
+		if err != nil {
+			return ctrlutil.RequeueError(err)
+		}
+		if !removed {
+			return ctrlutil.Requeue(true)
+		}
 	}
 
 	// reconcile delete
@@ -119,9 +293,9 @@ func (r *OceanComponentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	// reconcile apply
 	switch rctx.comp.Spec.State {
-	case oceanv1alpha1.OceanComponentStatePresent:
+	case oceanv1beta1.OceanComponentStatePresent:
 		return r.reconcilePresent(rctx)
-	case oceanv1alpha1.OceanComponentStateAbsent:
+	case oceanv1beta1.OceanComponentStateAbsent:
 		return r.reconcileAbsent(rctx)
 	default:
 		return ctrlutil.RequeueError(fmt.Errorf("unsupported component state: %v", rctx.comp.Spec.State))
@@ -130,130 +304,275 @@ func (r *OceanComponentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 func (r *OceanComponentReconciler) reconcilePresent(ctx *RequestContext) (ctrl.Result, error) {
 	// check whether the component is already installed
-	release, err := ctx.installer.Get(ctx.comp.Spec.Name)
+	release, err := ctx.installer.Get(ctx, ctx.comp.ReleaseName())
 	if err != nil {
 		if !installer.IsReleaseNotFound(err) {
 			return ctrlutil.RequeueError(err)
 		} else {
-			// component isn't present, install
-			return r.install(ctx)
+			// component isn't present, install once its dependencies are available
+			return r.installWhenReady(ctx)
 		}
 	}
 
 	// component is present, upgrade
-	if ctx.installer.IsUpgrade(ctx.comp, release) {
-		return r.upgrade(ctx)
+	desired, err := r.desiredComponent(ctx)
+	if err != nil {
+		return ctrlutil.RequeueError(err)
+	}
+	upgradePending := newCondition(
+		oceanv1beta1.OceanComponentConditionTypeUpgradePending,
+		corev1.ConditionFalse,
+		reasonNoUpgradePending,
+		"",
+	)
+	var retryAfter time.Duration
+	if ctx.installer.IsUpgrade(ctx, desired, release) {
+		held, wait, err := heldUpgrade(ctx.comp, desired, release, time.Now())
+		if err != nil {
+			return ctrlutil.RequeueError(err)
+		}
+		if held == nil {
+			return r.upgrade(ctx)
+		}
+		// upgrade is held back, keep reporting the installed release
+		ctx.log.Info("upgrade held back", "reason", held.Reason, "version", desired.Spec.Version)
+		upgradePending, retryAfter = held, wait
 	}
 
 	// component is present, and it's not an upgrade
 	switch release.Status {
-	case installer.ReleaseStatusFailed: // mark as failed, uninstall
-		deepCopy := ctx.comp.DeepCopy()
-		condition := newCondition(
-			oceanv1alpha1.OceanComponentConditionTypeFailure,
+	case installer.ReleaseStatusFailed: // mark as failed, roll back
+		if err = r.updateConditions(ctx, newCondition(
+			oceanv1beta1.OceanComponentConditionTypeFailure,
 			corev1.ConditionTrue,
 			installer.ReleaseStatusFailed.String(),
-			release.Description)
-		changed := setCondition(&(deepCopy.Status), *condition)
-		if changed {
-			if err = r.Client.Patch(ctx, deepCopy, client.MergeFrom(ctx.comp)); err != nil {
-				ctx.log.Error(err, "patch error")
+			release.Description),
+		); err != nil {
+			return ctrlutil.RequeueError(err)
+		}
+		return r.rollback(ctx, release)
+
+	case installer.ReleaseStatusProgressing: // progressing, requeue unless stuck
+		if r.pendingTimedOut(release, time.Now()) {
+			if err = r.updateConditions(ctx, newConditionf(
+				oceanv1beta1.OceanComponentConditionTypeFailure,
+				corev1.ConditionTrue,
+				reasonPendingTimedOut,
+				"Release has been pending since %s", release.LastDeployed.Format(time.RFC3339)),
+			); err != nil {
 				return ctrlutil.RequeueError(err)
 			}
+			return r.rollback(ctx, release)
 		}
-		return r.uninstall(ctx)
-
-	case installer.ReleaseStatusProgressing: // progressing, requeue
-		deepCopy := ctx.comp.DeepCopy()
-		condition := newCondition(
-			oceanv1alpha1.OceanComponentConditionTypeProgressing,
+		if err = r.updateConditions(ctx, newCondition(
+			oceanv1beta1.OceanComponentConditionTypeProgressing,
 			corev1.ConditionTrue,
 			installer.ReleaseStatusProgressing.String(),
-			release.Description)
-		changed := setCondition(&(deepCopy.Status), *condition)
-		if changed {
-			if err = r.Client.Patch(ctx, deepCopy, client.MergeFrom(ctx.comp)); err != nil {
-				ctx.log.Error(err, "patch error")
-				return ctrlutil.RequeueError(err)
-			}
+			release.Description),
+		); err != nil {
+			return ctrlutil.RequeueError(err)
 		}
-		return ctrlutil.RequeueAfterError(15*time.Second, err)
+		return ctrlutil.RequeueAfter(15 * time.Second)
 
 	case installer.ReleaseStatusUninstalled: // well, reinstall it
-		deepCopy := ctx.comp.DeepCopy()
-		condition := newCondition(
-			oceanv1alpha1.OceanComponentConditionTypeAvailable,
+		if err = r.updateConditions(ctx, newCondition(
+			oceanv1beta1.OceanComponentConditionTypeAvailable,
 			corev1.ConditionFalse,
 			installer.ReleaseStatusUninstalled.String(),
-			release.Description)
-		changed := setCondition(&(deepCopy.Status), *condition)
-		if changed { // update component
-			if err = r.Client.Patch(ctx, deepCopy, client.MergeFrom(ctx.comp)); err != nil {
-				ctx.log.Error(err, "patch error")
-				return ctrlutil.RequeueError(err)
-			}
-			if err = r.Client.Get(ctx, ctx.GetRequest().NamespacedName, ctx.comp); err != nil {
-				ctx.log.Error(err, "retrieve error")
-				return ctrlutil.RequeueError(err)
-			}
+			release.Description),
+		); err != nil {
+			return ctrlutil.RequeueError(err)
 		}
-		return r.install(ctx)
+		return r.installWhenReady(ctx)
 
-		// remaining conditions are Deployed and Unknown
+		// remaining conditions are Deployed, Superseded and Unknown
 		// continue on to component-specific condition
 	}
 
+	// check for drift before health, so corrected objects are accounted for
+	drifted, err := r.reconcileDrift(ctx, release)
+	if err != nil {
+		ctx.log.Error(err, "cannot reconcile drift")
+		return ctrlutil.RequeueError(err)
+	}
+
 	// check updated conditions
 	// note that underlying components may fail without triggering a reconciliation event
 
-	deepCopy := ctx.comp.DeepCopy()
-	changed := false
-
-	conditions, err := r.getCurrentConditions(ctx)
+	conditions, err := r.getCurrentConditions(ctx, release)
 	if err != nil {
 		ctx.log.Error(err, "cannot get current conditions")
 		return ctrlutil.RequeueError(err)
 	}
-	if len(conditions) > 0 {
-		for _, condition := range conditions {
-			up := setCondition(&(deepCopy.Status), *condition)
-			changed = changed || up
+	if drifted != nil {
+		conditions = append(conditions, drifted)
+	}
+	conditions = append(conditions,
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeProgressing,
+			corev1.ConditionFalse,
+			release.Status.String(),
+			release.Description,
+		),
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeFailure,
+			corev1.ConditionFalse,
+			release.Status.String(),
+			release.Description,
+		),
+		upgradePending,
+	)
+	if err = r.updateStatus(ctx, func(status *oceanv1beta1.OceanComponentStatus) {
+		setReleaseStatus(status, release)
+		status.ResolvedVersion = desired.Spec.Version
+	}, conditions...); err != nil {
+		return ctrlutil.RequeueError(err)
+	}
+
+	condition := getCondition(ctx.comp.Status, oceanv1beta1.OceanComponentConditionTypeReady)
+	if condition == nil || condition.Status != corev1.ConditionTrue {
+		return ctrlutil.Requeue(true)
+	}
+
+	// check again once the next maintenance window opens and, periodically,
+	// for newer versions satisfying a version constraint and for drift
+	var intervals []time.Duration
+	if retryAfter > 0 {
+		intervals = append(intervals, retryAfter)
+	}
+	if desired.Spec.Version != ctx.comp.Spec.Version {
+		intervals = append(intervals, versionResolutionInterval)
+	}
+	if drifted != nil && drifted.Reason != reasonDriftIgnored {
+		intervals = append(intervals, driftDetectionInterval)
+	}
+	if len(intervals) > 0 {
+		sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+		return ctrlutil.RequeueAfter(intervals[0])
+	}
+
+	return ctrlutil.Requeue(false)
+}
+
+// reconcileDrift detects drift of the objects of the given release according
+// to the drift policy of the component, and returns the Drifted condition
+// describing it, or nil if drift has never been detected for the component.
+func (r *OceanComponentReconciler) reconcileDrift(ctx *RequestContext,
+	release *installer.Release) (*oceanv1beta1.OceanComponentCondition, error) {
+	policy := ctx.comp.Spec.DriftPolicy
+	if policy == "" || policy == oceanv1beta1.DriftPolicyIgnore {
+		if getCondition(ctx.comp.Status, oceanv1beta1.OceanComponentConditionTypeDrifted) == nil {
+			return nil, nil
 		}
+		return newCondition(
+			oceanv1beta1.OceanComponentConditionTypeDrifted,
+			corev1.ConditionFalse,
+			reasonDriftIgnored,
+			"Drift detection is disabled",
+		), nil
 	}
-	if changed {
-		if err = r.Client.Patch(ctx, deepCopy, client.MergeFrom(ctx.comp)); err != nil {
-			ctx.log.Error(err, "patch error")
+
+	objs, err := parseManifest(release.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	drifted, err := detectDrift(ctx, r.Client, r.targetNamespace(ctx.comp), objs)
+	if err != nil {
+		return nil, err
+	}
+	if len(drifted) == 0 || policy != oceanv1beta1.DriftPolicyCorrect {
+		return driftCondition(drifted, false), nil
+	}
+
+	condition := driftCondition(drifted, true)
+	ctx.log.Info("correcting drift", "objects", condition.Message)
+	if err = correctDrift(ctx, r.Client, drifted); err != nil {
+		r.Recorder.Event(ctx.comp, corev1.EventTypeWarning, "DriftCorrectionFailed", err.Error())
+		return nil, err
+	}
+	r.Recorder.Event(ctx.comp, corev1.EventTypeNormal, reasonDriftCorrected, condition.Message)
+	return condition, nil
+}
+
+// reconcileSuspended refreshes the status of a suspended component without
+// acting on its release.
+func (r *OceanComponentReconciler) reconcileSuspended(ctx *RequestContext, source string) (ctrl.Result, error) {
+	ctx.log.Info("reconciliation suspended", "source", source)
+
+	conditions := []*oceanv1beta1.OceanComponentCondition{
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeSuspended,
+			corev1.ConditionTrue,
+			reasonSuspended,
+			fmt.Sprintf("Reconciliation is suspended by %s", source),
+		),
+	}
+	release, err := ctx.installer.Get(ctx, ctx.comp.ReleaseName())
+	if err != nil {
+		if !installer.IsReleaseNotFound(err) {
+			return ctrlutil.RequeueError(err)
+		}
+		conditions = append(conditions, newCondition(
+			oceanv1beta1.OceanComponentConditionTypeAvailable,
+			corev1.ConditionFalse,
+			"NotInstalled",
+			"Component is not installed",
+		))
+	} else {
+		current, err := r.getCurrentConditions(ctx, release)
+		if err != nil {
+			ctx.log.Error(err, "cannot get current conditions")
 			return ctrlutil.RequeueError(err)
 		}
+		conditions = append(conditions, current...)
 	}
 
-	condition := getCurrentCondition(deepCopy.Status)
-	requeue := true
-	if condition.Type == oceanv1alpha1.OceanComponentConditionTypeAvailable &&
-		condition.Status == corev1.ConditionTrue {
-		requeue = false
+	if err = r.updateStatus(ctx, func(status *oceanv1beta1.OceanComponentStatus) {
+		setReleaseStatus(status, release)
+	}, conditions...); err != nil {
+		return ctrlutil.RequeueError(err)
 	}
 
-	return ctrlutil.Requeue(requeue)
+	return ctrlutil.RequeueAfter(suspendedInterval)
+}
+
+// resume clears the Suspended condition of a component that is no longer
+// suspended.
+func (r *OceanComponentReconciler) resume(ctx *RequestContext) error {
+	condition := getCondition(ctx.comp.Status, oceanv1beta1.OceanComponentConditionTypeSuspended)
+	if condition == nil || condition.Status != corev1.ConditionTrue {
+		return nil
+	}
+	ctx.log.Info("reconciliation resumed")
+	return r.updateConditions(ctx, newCondition(
+		oceanv1beta1.OceanComponentConditionTypeSuspended,
+		corev1.ConditionFalse,
+		reasonResumed,
+		"Reconciliation is resumed",
+	))
 }
 
 func (r *OceanComponentReconciler) reconcileAbsent(ctx *RequestContext) (ctrl.Result, error) {
-	_, err := ctx.installer.Get(ctx.comp.Spec.Name)
+	_, err := ctx.installer.Get(ctx, ctx.comp.ReleaseName())
 	if err != nil {
 		if installer.IsReleaseNotFound(err) {
-			deepCopy := ctx.comp.DeepCopy()
-			condition := newCondition(
-				oceanv1alpha1.OceanComponentConditionTypeAvailable,
-				corev1.ConditionFalse,
-				installer.ReleaseStatusUninstalled.String(),
-				"Component not present",
-			)
-			changed := setCondition(&(deepCopy.Status), *condition)
-			if changed {
-				if err = r.Client.Patch(ctx, deepCopy, client.MergeFrom(ctx.comp)); err != nil {
-					ctx.log.Error(err, "patch error")
-					return ctrlutil.RequeueError(err)
-				}
+			if err = r.updateStatus(ctx, func(status *oceanv1beta1.OceanComponentStatus) {
+				setReleaseStatus(status, nil)
+			},
+				newCondition(
+					oceanv1beta1.OceanComponentConditionTypeAvailable,
+					corev1.ConditionFalse,
+					installer.ReleaseStatusUninstalled.String(),
+					"Component not present",
+				),
+				newCondition(
+					oceanv1beta1.OceanComponentConditionTypeProgressing,
+					corev1.ConditionFalse,
+					installer.ReleaseStatusUninstalled.String(),
+					"Component not present",
+				),
+			); err != nil {
+				return ctrlutil.RequeueError(err)
 			}
 			return ctrlutil.NoRequeue()
 		}
@@ -263,90 +582,213 @@ func (r *OceanComponentReconciler) reconcileAbsent(ctx *RequestContext) (ctrl.Re
 	return r.uninstall(ctx)
 }
 
+func (r *OceanComponentReconciler) installWhenReady(ctx *RequestContext) (ctrl.Result, error) {
+	ready, err := r.dependenciesReady(ctx)
+	if err != nil {
+		return ctrlutil.RequeueError(err)
+	}
+	if !ready {
+		return ctrlutil.NoRequeue() // dependencies trigger a reconciliation when available
+	}
+	return r.install(ctx)
+}
+
+// dependenciesReady reports whether all dependencies of the component are
+// available. Otherwise, the component's conditions explain what it is
+// waiting for.
+func (r *OceanComponentReconciler) dependenciesReady(ctx *RequestContext) (bool, error) {
+	if len(ctx.comp.Spec.DependsOn) == 0 {
+		return true, nil
+	}
+
+	cycle, err := findDependencyCycle(ctx.comp.Name, func(name string) ([]string, error) {
+		if name == ctx.comp.Name {
+			return ctx.comp.Spec.DependsOn, nil
+		}
+		dep := new(oceanv1beta1.OceanComponent)
+		key := types.NamespacedName{Namespace: ctx.comp.Namespace, Name: name}
+		if err := r.Client.Get(ctx, key, dep); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		return dep.Spec.DependsOn, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if cycle != nil {
+		return false, r.updateConditions(ctx, newConditionf(
+			oceanv1beta1.OceanComponentConditionTypeFailure,
+			corev1.ConditionTrue,
+			reasonDependencyCycle,
+			"Dependency cycle: %s", strings.Join(cycle, " -> "),
+		))
+	}
+
+	var waiting []string
+	for _, name := range ctx.comp.Spec.DependsOn {
+		dep := new(oceanv1beta1.OceanComponent)
+		key := types.NamespacedName{Namespace: ctx.comp.Namespace, Name: name}
+		if err = r.Client.Get(ctx, key, dep); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return false, err
+			}
+			waiting = append(waiting, fmt.Sprintf("%s (not found)", name))
+			continue
+		}
+		if c := getCondition(dep.Status, oceanv1beta1.OceanComponentConditionTypeAvailable); c == nil || c.Status != corev1.ConditionTrue {
+			waiting = append(waiting, name)
+		}
+	}
+	if len(waiting) == 0 {
+		return true, r.updateConditions(ctx, newCondition(
+			oceanv1beta1.OceanComponentConditionTypeFailure,
+			corev1.ConditionFalse,
+			reasonDependenciesReady,
+			"All dependencies are available",
+		))
+	}
+
+	return false, r.updateConditions(ctx,
+		newConditionf(
+			oceanv1beta1.OceanComponentConditionTypeProgressing,
+			corev1.ConditionTrue,
+			reasonWaitingForDependencies,
+			"Waiting for dependencies to become available: %s", strings.Join(waiting, ", "),
+		),
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeFailure,
+			corev1.ConditionFalse,
+			reasonWaitingForDependencies,
+			"",
+		),
+	)
+}
+
+// dependentsRemoved reports whether all components depending on the component
+// have been removed. Otherwise, the component's conditions explain what it is
+// waiting for.
+func (r *OceanComponentReconciler) dependentsRemoved(ctx *RequestContext) (bool, error) {
+	list := new(oceanv1beta1.OceanComponentList)
+	if err := r.Client.List(ctx, list,
+		client.InNamespace(ctx.comp.Namespace),
+		client.MatchingFields{dependsOnIndexKey: ctx.comp.Name},
+	); err != nil {
+		return false, err
+	}
+
+	var waiting []string
+	for _, item := range list.Items {
+		if item.UID == ctx.comp.UID {
+			continue
+		}
+		removing := item.Spec.State == oceanv1beta1.OceanComponentStateAbsent || ctrlutil.IsBeingDeleted(&item)
+		available := getCondition(item.Status, oceanv1beta1.OceanComponentConditionTypeAvailable)
+		if !removing || (available != nil && available.Status == corev1.ConditionTrue) {
+			waiting = append(waiting, item.Name)
+		}
+	}
+	if len(waiting) == 0 {
+		return true, nil
+	}
+
+	sort.Strings(waiting)
+	return false, r.updateConditions(ctx, newConditionf(
+		oceanv1beta1.OceanComponentConditionTypeProgressing,
+		corev1.ConditionTrue,
+		reasonWaitingForDependents,
+		"Waiting for dependents to be removed: %s", strings.Join(waiting, ", "),
+	))
+}
+
 func (r *OceanComponentReconciler) install(ctx *RequestContext) (ctrl.Result, error) {
 	ctx.log.Info("installing")
 
-	deepCopy := ctx.comp.DeepCopy()
-	condition := newCondition(
-		oceanv1alpha1.OceanComponentConditionTypeProgressing,
+	if err := r.updateConditions(ctx, newCondition(
+		oceanv1beta1.OceanComponentConditionTypeProgressing,
 		corev1.ConditionTrue,
 		"Installing",
 		"Install started",
-	)
-	changed := setCondition(&(deepCopy.Status), *condition)
-	if changed {
-		if err := r.Client.Patch(ctx, deepCopy, client.MergeFrom(ctx.comp)); err != nil {
-			ctx.log.Error(err, "patch error")
-			return ctrlutil.RequeueError(err)
-		}
+	)); err != nil {
+		return ctrlutil.RequeueError(err)
 	}
 
-	if err := r.ensureNamespace(ctx, deepCopy.Namespace); err != nil {
-		ctx.log.Error(err, "unable to create namespace", "namespace", r.Namespace)
+	if err := r.ensureNamespace(ctx, r.targetNamespace(ctx.comp)); err != nil {
+		ctx.log.Error(err, "unable to create namespace", "namespace", r.targetNamespace(ctx.comp))
 		return ctrlutil.RequeueError(err)
 	}
 
-	ephemeralCopy := deepCopy.DeepCopy()
-	if err := r.setSpecValues(ctx, ephemeralCopy); err != nil {
-		return ctrlutil.RequeueError(err)
+	desired, err := r.desiredComponent(ctx)
+	if err != nil {
+		return r.operationFailed(ctx, "VersionResolutionFailed", err)
 	}
-	_, installErr := ctx.installer.Install(ephemeralCopy)
+	opCtx, cancel := r.operationContext(ctx)
+	defer cancel()
+	release, installErr := ctx.installer.Install(opCtx, desired)
 	if installErr != nil {
 		ctx.log.Error(installErr, "installation failed")
-		return ctrlutil.RequeueError(installErr)
+		return r.operationFailed(ctx, failureReason(installErr, "InstallFailed"), installErr)
 	}
 
-	condition = newCondition(
-		oceanv1alpha1.OceanComponentConditionTypeAvailable,
-		corev1.ConditionTrue,
-		"Installed",
-		"Install finished",
-	)
-	changed = setCondition(&(deepCopy.Status), *condition)
-	if changed {
-		if err := r.Client.Patch(ctx, deepCopy, client.MergeFrom(ctx.comp)); err != nil {
-			ctx.log.Error(err, "patch error")
-			return ctrlutil.RequeueError(err)
-		}
+	if err = r.updateStatus(ctx, func(status *oceanv1beta1.OceanComponentStatus) {
+		setOperationStatus(status, release, desired.Spec.Values)
+		status.ResolvedVersion = desired.Spec.Version
+	},
+		// availability is left to the health checks
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeProgressing,
+			corev1.ConditionTrue,
+			"Installed",
+			"Install finished, waiting for the component to become available",
+		),
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeFailure,
+			corev1.ConditionFalse,
+			"Installed",
+			"Install finished",
+		),
+	); err != nil {
+		return ctrlutil.RequeueError(err)
 	}
 
-	return ctrlutil.RequeueAfter(time.Minute)
+	return ctrlutil.RequeueAfter(availabilityInterval)
 }
 
 func (r *OceanComponentReconciler) uninstall(ctx *RequestContext) (ctrl.Result, error) {
 	ctx.log.Info("uninstalling")
 
-	deepCopy := ctx.comp.DeepCopy()
-	condition := newCondition(
-		oceanv1alpha1.OceanComponentConditionTypeProgressing,
+	if err := r.updateConditions(ctx, newCondition(
+		oceanv1beta1.OceanComponentConditionTypeProgressing,
 		corev1.ConditionTrue,
 		"Uninstalling",
 		"Uninstall started",
-	)
-	changed := setCondition(&(deepCopy.Status), *condition)
-	if changed {
-		if err := r.Client.Patch(ctx, deepCopy, client.MergeFrom(ctx.comp)); err != nil {
-			ctx.log.Error(err, "patch error")
-			return ctrlutil.RequeueError(err)
-		}
+	)); err != nil {
+		return ctrlutil.RequeueError(err)
 	}
-	uninstallErr := ctx.installer.Uninstall(deepCopy)
+
+	opCtx, cancel := r.operationContext(ctx)
+	defer cancel()
+	uninstallErr := ctx.installer.Uninstall(opCtx, ctx.comp.DeepCopy())
 	if uninstallErr != nil {
-		return ctrlutil.RequeueError(uninstallErr)
+		return r.operationFailed(ctx, failureReason(uninstallErr, "UninstallFailed"), uninstallErr)
 	}
 
-	condition = newCondition(
-		oceanv1alpha1.OceanComponentConditionTypeAvailable,
-		corev1.ConditionTrue,
-		"Uninstalled",
-		"Uninstall finished",
-	)
-	changed = setCondition(&(deepCopy.Status), *condition)
-	if changed {
-		if err := r.Client.Patch(ctx, deepCopy, client.MergeFrom(ctx.comp)); err != nil {
-			ctx.log.Error(err, "patch error")
-			return ctrlutil.RequeueError(err)
-		}
+	if err := r.updateStatus(ctx, func(status *oceanv1beta1.OceanComponentStatus) {
+		setOperationStatus(status, nil, nil)
+	},
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeAvailable,
+			corev1.ConditionFalse,
+			"Uninstalled",
+			"Uninstall finished",
+		),
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeProgressing,
+			corev1.ConditionFalse,
+			"Uninstalled",
+			"Uninstall finished",
+		),
+	); err != nil {
+		return ctrlutil.RequeueError(err)
 	}
 
 	return ctrlutil.RequeueAfter(time.Minute)
@@ -355,105 +797,280 @@ func (r *OceanComponentReconciler) uninstall(ctx *RequestContext) (ctrl.Result,
 func (r *OceanComponentReconciler) upgrade(ctx *RequestContext) (ctrl.Result, error) {
 	ctx.log.Info("upgrading")
 
-	deepCopy := ctx.comp.DeepCopy()
-	condition := newCondition(
-		oceanv1alpha1.OceanComponentConditionTypeProgressing,
+	if err := r.updateConditions(ctx, newCondition(
+		oceanv1beta1.OceanComponentConditionTypeProgressing,
 		corev1.ConditionTrue,
 		"Upgrading",
 		"Upgrade started",
-	)
-	changed := setCondition(&(deepCopy.Status), *condition)
-	if changed {
-		if err := r.Client.Patch(ctx, deepCopy, client.MergeFrom(ctx.comp)); err != nil {
-			ctx.log.Error(err, "patch error")
-			return ctrlutil.RequeueError(err)
-		}
+	)); err != nil {
+		return ctrlutil.RequeueError(err)
 	}
 
-	ephemeralCopy := deepCopy.DeepCopy()
-	if err := r.setSpecValues(ctx, ephemeralCopy); err != nil {
-		return ctrlutil.RequeueError(err)
+	desired, err := r.desiredComponent(ctx)
+	if err != nil {
+		return r.operationFailed(ctx, "VersionResolutionFailed", err)
 	}
-	_, upgradeErr := ctx.installer.Upgrade(ephemeralCopy)
+	opCtx, cancel := r.operationContext(ctx)
+	defer cancel()
+	release, upgradeErr := ctx.installer.Upgrade(opCtx, desired)
 	if upgradeErr != nil {
-		return ctrlutil.RequeueError(upgradeErr)
+		return r.operationFailed(ctx, failureReason(upgradeErr, "UpgradeFailed"), upgradeErr)
+	}
+
+	if err = r.updateStatus(ctx, func(status *oceanv1beta1.OceanComponentStatus) {
+		setOperationStatus(status, release, desired.Spec.Values)
+		status.ResolvedVersion = desired.Spec.Version
+	},
+		// availability is left to the health checks
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeProgressing,
+			corev1.ConditionTrue,
+			"Upgraded",
+			"Upgrade finished, waiting for the component to become available",
+		),
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeFailure,
+			corev1.ConditionFalse,
+			"Upgraded",
+			"Upgrade finished",
+		),
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeUpgradePending,
+			corev1.ConditionFalse,
+			"Upgraded",
+			"Upgrade finished",
+		),
+	); err != nil {
+		return ctrlutil.RequeueError(err)
 	}
 
-	condition = newCondition(
-		oceanv1alpha1.OceanComponentConditionTypeAvailable,
+	return ctrlutil.RequeueAfter(availabilityInterval)
+}
+
+// rollback rolls the given failed release back to its last deployed revision
+// or, when there is none, uninstalls it so that it is installed again.
+func (r *OceanComponentReconciler) rollback(ctx *RequestContext, failed *installer.Release) (ctrl.Result, error) {
+	history, err := ctx.installer.History(ctx, ctx.comp.ReleaseName())
+	if err != nil && !installer.IsReleaseNotFound(err) && !installer.IsNotImplemented(err) {
+		return ctrlutil.RequeueError(err)
+	}
+	target := lastDeployedRevision(history, failed.Revision)
+	if target == nil {
+		ctx.log.Info("no deployed revision to roll back to")
+		return r.uninstall(ctx)
+	}
+
+	ctx.log.Info("rolling back", "from", failed.Revision, "to", target.Revision)
+	if err = r.updateConditions(ctx, newCondition(
+		oceanv1beta1.OceanComponentConditionTypeProgressing,
 		corev1.ConditionTrue,
-		"Upgraded",
-		"Upgrade finished",
-	)
-	changed = setCondition(&(deepCopy.Status), *condition)
-	if changed {
-		if err := r.Client.Patch(ctx, deepCopy, client.MergeFrom(ctx.comp)); err != nil {
-			ctx.log.Error(err, "patch error")
-			return ctrlutil.RequeueError(err)
-		}
+		"RollingBack",
+		"Rollback started",
+	)); err != nil {
+		return ctrlutil.RequeueError(err)
+	}
+
+	opCtx, cancel := r.operationContext(ctx)
+	defer cancel()
+	release, rollbackErr := ctx.installer.Rollback(opCtx, ctx.comp.DeepCopy(), target.Revision)
+	if rollbackErr != nil {
+		r.Recorder.Eventf(ctx.comp, corev1.EventTypeWarning, "RollbackFailed",
+			"Rollback from revision %d to revision %d failed: %v", failed.Revision, target.Revision, rollbackErr)
+		return r.operationFailed(ctx, failureReason(rollbackErr, "RollbackFailed"), rollbackErr)
+	}
+	r.Recorder.Eventf(ctx.comp, corev1.EventTypeNormal, "RolledBack",
+		"Rolled back from revision %d (version %s) to revision %d (version %s)",
+		failed.Revision, failed.Version, target.Revision, target.Version)
+
+	desired, err := r.desiredComponent(ctx)
+	if err != nil {
+		return ctrlutil.RequeueError(err)
+	}
+	message := fmt.Sprintf("Rolled back to revision %d", target.Revision)
+	if err = r.updateStatus(ctx, func(status *oceanv1beta1.OceanComponentStatus) {
+		setOperationStatus(status, release, releaseValues(release))
+		status.LastRollback = newRollbackStatus(ctx.comp, desired, failed, target, *status.LastOperationTime)
+	},
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeProgressing,
+			corev1.ConditionFalse,
+			"RolledBack",
+			message,
+		),
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeFailure,
+			corev1.ConditionFalse,
+			"RolledBack",
+			message,
+		),
+	); err != nil {
+		return ctrlutil.RequeueError(err)
 	}
 
 	return ctrlutil.RequeueAfter(time.Minute)
 }
 
+// pendingTimedOut reports whether the given pending release has been pending
+// for longer than the operation timeout, e.g. because the operator stopped in
+// the middle of an operation. Releases pending since an unknown time never
+// time out.
+func (r *OceanComponentReconciler) pendingTimedOut(release *installer.Release, now time.Time) bool {
+	if release.LastDeployed.IsZero() {
+		return false
+	}
+	timeout := r.OperationTimeout
+	if timeout <= 0 {
+		timeout = defaultPendingTimeout
+	}
+	return now.Sub(release.LastDeployed) > timeout
+}
+
+// operationFailed marks the component as failing due to the given error, and
+// requeues the request.
+func (r *OceanComponentReconciler) operationFailed(ctx *RequestContext,
+	reason string, err error) (ctrl.Result, error) {
+	if updateErr := r.updateConditions(ctx,
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeFailure,
+			corev1.ConditionTrue,
+			reason,
+			err.Error(),
+		),
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeProgressing,
+			corev1.ConditionFalse,
+			reason,
+			err.Error(),
+		),
+	); updateErr != nil {
+		ctx.log.Error(updateErr, "cannot update conditions")
+	}
+	return ctrlutil.RequeueError(err)
+}
+
+// operationContext returns the context of an installer operation, which
+// expires after the operation timeout.
+func (r *OceanComponentReconciler) operationContext(ctx *RequestContext) (context.Context, context.CancelFunc) {
+	if r.OperationTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.OperationTimeout)
+}
+
+// updateConditions sets the given conditions on the component's status, and
+// patches it if anything changed.
+func (r *OceanComponentReconciler) updateConditions(ctx *RequestContext,
+	conditions ...*oceanv1beta1.OceanComponentCondition) error {
+	return r.updateStatus(ctx, nil, conditions...)
+}
+
+// updateStatus applies the given mutation and conditions to the component's
+// status, and patches it if anything changed. The Ready condition and the
+// observed generation are kept up to date.
+func (r *OceanComponentReconciler) updateStatus(ctx *RequestContext,
+	mutate func(status *oceanv1beta1.OceanComponentStatus),
+	conditions ...*oceanv1beta1.OceanComponentCondition) error {
+	base := ctx.comp.DeepCopy()
+	status := &ctx.comp.Status
+	generation := ctx.comp.Generation
+
+	if mutate != nil {
+		mutate(status)
+	}
+	for _, condition := range conditions {
+		condition.ObservedGeneration = generation
+		setCondition(status, *condition)
+	}
+	setReadyCondition(status, generation)
+	status.ObservedGeneration = generation
+
+	if equality.Semantic.DeepEqual(base.Status, ctx.comp.Status) {
+		return nil
+	}
+	if err := r.Client.Status().Patch(ctx, ctx.comp, client.MergeFrom(base)); err != nil {
+		ctx.log.Error(err, "patch error")
+		return err
+	}
+	return nil
+}
+
 func (r *OceanComponentReconciler) ensureNamespace(ctx *RequestContext, namespace string) error {
 	if namespace == "" {
 		namespace = r.Namespace
 	}
-	ns := new(corev1.Namespace)
-	key := types.NamespacedName{Name: namespace}
-	ctx.log.V(1).Info("checking existence", "namespace", namespace)
-	err := r.Client.Get(ctx, key, ns)
-	if apierrors.IsNotFound(err) {
-		ns.Name = namespace
-		ctx.log.Info("creating", "namespace", namespace)
-		return r.Client.Create(ctx, ns)
-	}
-	return err
+	return ensureNamespace(ctx, r.Client, ctx.log, namespace)
 }
 
-// getCurrentConditions examines the current environment and returns a condition
-// for the component.
-func (r *OceanComponentReconciler) getCurrentConditions(ctx *RequestContext) ([]*oceanv1alpha1.OceanComponentCondition, error) {
-	objName := ctx.GetRequest().NamespacedName
-	switch ctx.comp.Spec.Name {
-	case oceanv1alpha1.OceanControllerComponentName, oceanv1alpha1.LegacyOceanControllerComponentName:
-		return getOceanControllerConditions(ctx, r.Client, objName)
-	case oceanv1alpha1.MetricsServerComponentName:
-		return getMetricsServerConditions(ctx, r.Client, objName)
-	default:
-		// (a) check helm
-		// (b) return not installed
-		return []*oceanv1alpha1.OceanComponentCondition{
-			newCondition(
-				oceanv1alpha1.OceanComponentConditionTypeAvailable,
-				corev1.ConditionFalse,
-				installer.ReleaseStatusUninstalled.String(),
-				"Component not installed",
-			),
-		}, nil
+// getCurrentConditions evaluates the component's health checks and returns
+// its Available and Degraded conditions. Components without health checks are
+// checked for the workloads of their deployed release, and are available once
+// their release is deployed if it has none.
+func (r *OceanComponentReconciler) getCurrentConditions(ctx *RequestContext,
+	release *installer.Release) ([]*oceanv1beta1.OceanComponentCondition, error) {
+	checks := ctx.comp.Spec.HealthChecks
+	if len(checks) == 0 && release.Status == installer.ReleaseStatusDeployed {
+		var err error
+		if checks, err = releaseHealthChecks(release); err != nil {
+			return nil, err
+		}
+	}
+	if len(checks) > 0 {
+		return evaluateHealthChecks(ctx, r.Client, r.targetNamespace(ctx.comp), checks)
+	}
+
+	status := corev1.ConditionFalse
+	if release.Status == installer.ReleaseStatusDeployed {
+		status = corev1.ConditionTrue
 	}
+	return []*oceanv1beta1.OceanComponentCondition{
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeAvailable,
+			status,
+			release.Status.String(),
+			release.Description,
+		),
+		newCondition(
+			oceanv1beta1.OceanComponentConditionTypeDegraded,
+			corev1.ConditionFalse,
+			release.Status.String(),
+			"",
+		),
+	}, nil
 }
 
-func (r *OceanComponentReconciler) unsupportedType(ctx *RequestContext) (ctrl.Result, error) {
-	deepCopy := ctx.comp.DeepCopy()
+// unsupportedType marks the component as failing because no installer of its
+// type, or of its plugin, is available.
+func (r *OceanComponentReconciler) unsupportedType(ctx *RequestContext, err error) (ctrl.Result, error) {
 	condition := newCondition(
-		oceanv1alpha1.OceanComponentConditionTypeFailure,
+		oceanv1beta1.OceanComponentConditionTypeFailure,
 		corev1.ConditionTrue,
 		installer.ReleaseStatusFailed.String(),
-		"Only Helm charts are supported",
+		err.Error(),
 	)
-	changed := setCondition(&(deepCopy.Status), *condition)
-	if changed {
-		if err := r.Client.Patch(ctx, deepCopy, client.MergeFrom(ctx.comp)); err != nil {
-			ctx.log.Error(err, "patch error")
-			return ctrlutil.RequeueError(err)
-		}
+	switch {
+	case !installer.IsInstallerNotFound(err):
+		// the installer failed to initialize
+	case ctx.comp.Spec.Type == oceanv1beta1.OceanComponentTypePlugin:
+		// plugins are discovered once, when the operator starts
+		condition.Reason = reasonPluginNotFound
+		condition.Message = fmt.Sprintf("Plugin %q was not discovered by the operator",
+			ctx.comp.Spec.Plugin.Name)
+	default:
+		condition.Message = fmt.Sprintf("Unsupported component type %q, supported types are %s, %s, %s and %s",
+			ctx.comp.Spec.Type,
+			oceanv1beta1.OceanComponentTypeHelm,
+			oceanv1beta1.OceanComponentTypeManifest,
+			oceanv1beta1.OceanComponentTypeKustomize,
+			oceanv1beta1.OceanComponentTypePlugin,
+		)
+	}
+	if err := r.updateConditions(ctx, condition); err != nil {
+		return ctrlutil.RequeueError(err)
 	}
 	return ctrlutil.NoRequeue()
 }
 
-func (r *OceanComponentReconciler) setInitialValues(comp *oceanv1alpha1.OceanComponent) (bool, error) {
+func (r *OceanComponentReconciler) setInitialValues(comp *oceanv1beta1.OceanComponent) (bool, error) {
 	changed := false
 	if !ctrlutil.IsBeingDeleted(comp) {
 		changed = ctrlutil.AddFinalizer(comp, OperatorFinalizerName)
@@ -465,19 +1082,70 @@ func (r *OceanComponentReconciler) setInitialValues(comp *oceanv1alpha1.OceanCom
 		comp.Annotations[OperatorVersionAnnotation] = version.String()
 		changed = true
 	}
-	return changed, nil
+	normalized, err := comp.NormalizeValues()
+	if err != nil {
+		return false, err
+	}
+	return changed || normalized, nil
 }
 
+// setSpecValues sets the effective values of the component. Referenced values
+// are merged in order, followed by the inline values and, finally, by values
+// built for specific components.
 func (r *OceanComponentReconciler) setSpecValues(ctx *RequestContext,
-	comp *oceanv1alpha1.OceanComponent) (err error) {
+	comp *oceanv1beta1.OceanComponent) error {
+	v := values.FromJSON(comp.Spec.Values)
+
+	if len(comp.Spec.ValuesFrom) > 0 {
+		refs, err := values.FromReferences(ctx, r.Client, comp.Namespace, comp.Spec.ValuesFrom)
+		if err != nil {
+			return err
+		}
+		v, err = values.Merge(append(refs, v)...)
+		if err != nil {
+			return err
+		}
+	}
+
 	switch comp.Spec.Name {
-	case oceanv1alpha1.OceanControllerComponentName, oceanv1alpha1.LegacyOceanControllerComponentName:
-		comp.Spec.Values, err = values.ForOceanController(ctx, comp.Spec.Values,
+	case oceanv1beta1.OceanControllerComponentName, oceanv1beta1.LegacyOceanControllerComponentName:
+		var err error
+		v, err = values.ForOceanController(ctx, v,
 			values.NewOceanControllerBuilder(values.NewOceanBaseBuilder().WithClient(r.Client)))
-		return err
+		if err != nil {
+			return err
+		}
 	default:
-		return nil
+		if len(comp.Spec.ValuesFrom) == 0 {
+			return nil // nothing to do
+		}
 	}
+
+	var err error
+	comp.Spec.Values, err = values.ToJSON(v)
+	return err
+}
+
+// desiredComponent returns a copy of the component as it should be installed,
+// with its effective values and its version resolved. It is computed once per
+// request.
+func (r *OceanComponentReconciler) desiredComponent(ctx *RequestContext) (*oceanv1beta1.OceanComponent, error) {
+	if ctx.desired != nil {
+		return ctx.desired, nil
+	}
+
+	desired := ctx.comp.DeepCopy()
+	if err := r.setSpecValues(ctx, desired); err != nil {
+		return nil, err
+	}
+	version, err := ctx.installer.ResolveVersion(ctx, desired)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve version %q: %w", desired.Spec.Version, err)
+	}
+	desired.Spec.Version = version
+
+	ctx.desired = desired
+	return desired, nil
 }
 
 func (r *OceanComponentReconciler) newContext(ctx context.Context, req ctrl.Request) *RequestContext {
@@ -497,16 +1165,32 @@ func (r *OceanComponentReconciler) newContext(ctx context.Context, req ctrl.Requ
 	}
 }
 
+// targetNamespace returns the namespace the given component is installed into.
+func (r *OceanComponentReconciler) targetNamespace(comp *oceanv1beta1.OceanComponent) string {
+	if comp.Spec.TargetNamespace != "" {
+		return comp.Spec.TargetNamespace
+	}
+	return r.Namespace
+}
+
 func (r *OceanComponentReconciler) newInstaller(ctx *RequestContext) (installer.Installer, error) {
 	options := []installer.InstallerOption{
-		installer.WithNamespace(r.Namespace),
+		installer.WithNamespace(r.targetNamespace(ctx.comp)),
 		installer.WithClientGetter(r.ClientGetter),
+		installer.WithCache(r.Cache),
 		installer.WithLogger(ctx.log),
 	}
 	switch compType := ctx.comp.Spec.Type; compType {
-	case oceanv1alpha1.OceanComponentTypeHelm:
+	case oceanv1beta1.OceanComponentTypeHelm, oceanv1beta1.OceanComponentTypeManifest,
+		oceanv1beta1.OceanComponentTypeKustomize:
 		return installer.GetInstance(string(compType), options...)
+	case oceanv1beta1.OceanComponentTypePlugin:
+		if ctx.comp.Spec.Plugin == nil {
+			return nil, fmt.Errorf("component %s has no plugin", ctx.comp.Name)
+		}
+		return installer.GetInstance(plugin.InstallerName(ctx.comp.Spec.Plugin.Name), options...)
 	default:
-		return nil, fmt.Errorf("unsupported component type: %v", ctx.comp.Spec.Type)
+		return nil, fmt.Errorf("%w: unsupported component type: %v",
+			installer.ErrInstallerNotFound, ctx.comp.Spec.Type)
 	}
 }
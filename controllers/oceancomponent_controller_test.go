@@ -0,0 +1,58 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/log"
+	"github.com/spotinst/ocean-operator/pkg/tide"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUnsupportedType(t *testing.T) {
+	newComponent := func(spec oceanv1beta1.OceanComponentSpec) *oceanv1beta1.OceanComponent {
+		return &oceanv1beta1.OceanComponent{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: oceanv1beta1.NamespaceSystem},
+			Spec:       spec,
+		}
+	}
+	unsupported := func(tt *testing.T, comp *oceanv1beta1.OceanComponent) *oceanv1beta1.OceanComponentCondition {
+		r := &OceanComponentReconciler{
+			Client: fake.NewClientBuilder().WithScheme(tide.DefaultScheme()).WithObjects(comp).Build(),
+			Log:    log.NullLogger,
+		}
+		ctx := r.newContext(context.Background(), ctrl.Request{})
+		ctx.comp = comp
+
+		_, err := r.newInstaller(ctx)
+		assert.Error(tt, err)
+		_, err = r.unsupportedType(ctx, err)
+		assert.NoError(tt, err)
+		return getCondition(comp.Status, oceanv1beta1.OceanComponentConditionTypeFailure)
+	}
+
+	t.Run("whenUnknownType", func(tt *testing.T) {
+		condition := unsupported(tt, newComponent(oceanv1beta1.OceanComponentSpec{Type: "Jsonnet"}))
+		if assert.NotNil(tt, condition) {
+			assert.Equal(tt, `Unsupported component type "Jsonnet", supported types are `+
+				"Helm, Manifest, Kustomize and Plugin", condition.Message)
+		}
+	})
+
+	t.Run("whenPluginNotFound", func(tt *testing.T) {
+		condition := unsupported(tt, newComponent(oceanv1beta1.OceanComponentSpec{
+			Type:   oceanv1beta1.OceanComponentTypePlugin,
+			Plugin: &oceanv1beta1.PluginOptions{Name: "jsonnet"},
+		}))
+		if assert.NotNil(tt, condition) {
+			assert.Equal(tt, reasonPluginNotFound, condition.Reason)
+			assert.Equal(tt, `Plugin "jsonnet" was not discovered by the operator`, condition.Message)
+		}
+	})
+}
@@ -0,0 +1,350 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// These are reasons of conditions set while detecting drift.
+const (
+	reasonNoDrift        = "NoDrift"
+	reasonDriftDetected  = "DriftDetected"
+	reasonDriftCorrected = "DriftCorrected"
+	reasonDriftIgnored   = "DriftIgnored"
+)
+
+// driftIgnoredFields are top-level fields of rendered objects that are not
+// compared with the live objects.
+var driftIgnoredFields = map[string]bool{
+	"apiVersion": true,
+	"kind":       true,
+	"metadata":   true,
+	"status":     true,
+	"stringData": true, // write-only, merged into data
+}
+
+// driftedObject is a rendered object whose live state differs from it.
+type driftedObject struct {
+	obj     *unstructured.Unstructured
+	missing bool
+}
+
+func (x driftedObject) String() string {
+	state := "modified"
+	if x.missing {
+		state = "missing"
+	}
+	return fmt.Sprintf("%s %s is %s", x.obj.GetKind(), objectName(x.obj), state)
+}
+
+// parseManifest parses the objects of the given multi-document YAML manifest.
+func parseManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	decoder := yamlutil.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+	var objs []*unstructured.Unstructured
+	for {
+		obj := new(unstructured.Unstructured)
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, fmt.Errorf("invalid manifest: %w", err)
+		}
+		if len(obj.Object) > 0 {
+			objs = append(objs, obj)
+		}
+	}
+}
+
+// detectDrift compares the given rendered objects with the live objects, and
+// returns those that are missing or modified. Namespaced objects without a
+// namespace are looked up in the given namespace.
+func detectDrift(ctx context.Context, c client.Client, namespace string,
+	objs []*unstructured.Unstructured) ([]driftedObject, error) {
+	var drifted []driftedObject
+	for _, obj := range objs {
+		if obj.GetNamespace() == "" {
+			namespaced, err := isNamespaced(c.RESTMapper(), obj)
+			if err != nil {
+				return nil, err
+			}
+			if namespaced {
+				obj.SetNamespace(namespace)
+			}
+		}
+
+		live := new(unstructured.Unstructured)
+		live.SetGroupVersionKind(obj.GroupVersionKind())
+		if err := c.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			drifted = append(drifted, driftedObject{obj: obj, missing: true})
+			continue
+		}
+		if !matchesLive(obj, live, patchMetaFor(c.Scheme(), obj)) {
+			drifted = append(drifted, driftedObject{obj: obj})
+		}
+	}
+	return drifted, nil
+}
+
+// correctDrift re-applies the rendered state of the given drifted objects.
+// Objects of built-in kinds are patched with a strategic merge patch, which
+// merges lists by their merge key, so that entries added by others (e.g.
+// injected sidecars) are kept. Other objects are patched with a merge patch.
+func correctDrift(ctx context.Context, c client.Client, drifted []driftedObject) error {
+	for _, d := range drifted {
+		obj := d.obj.DeepCopy()
+		var err error
+		switch {
+		case d.missing:
+			err = c.Create(ctx, obj)
+		case patchMetaFor(c.Scheme(), obj) != nil:
+			var data []byte
+			if data, err = json.Marshal(obj.Object); err == nil {
+				err = c.Patch(ctx, obj, client.RawPatch(types.StrategicMergePatchType, data))
+			}
+		default:
+			err = c.Patch(ctx, obj, client.Merge)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to correct %s %s: %w", obj.GetKind(), objectName(obj), err)
+		}
+	}
+	return nil
+}
+
+// driftCondition returns the Drifted condition describing the given drifted
+// objects, which have been corrected if so stated.
+func driftCondition(drifted []driftedObject, corrected bool) *oceanv1beta1.OceanComponentCondition {
+	if len(drifted) == 0 {
+		return newCondition(
+			oceanv1beta1.OceanComponentConditionTypeDrifted,
+			corev1.ConditionFalse,
+			reasonNoDrift,
+			"",
+		)
+	}
+	msgs := make([]string, 0, len(drifted))
+	for _, d := range drifted {
+		msgs = append(msgs, d.String())
+	}
+	if corrected {
+		return newCondition(
+			oceanv1beta1.OceanComponentConditionTypeDrifted,
+			corev1.ConditionFalse,
+			reasonDriftCorrected,
+			"Corrected: "+strings.Join(msgs, "; "),
+		)
+	}
+	return newCondition(
+		oceanv1beta1.OceanComponentConditionTypeDrifted,
+		corev1.ConditionTrue,
+		reasonDriftDetected,
+		strings.Join(msgs, "; "),
+	)
+}
+
+// isNamespaced reports whether the kind of the given object is namespaced.
+func isNamespaced(mapper apimeta.RESTMapper, obj *unstructured.Unstructured) (bool, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, fmt.Errorf("unable to map kind %s: %w", gvk, err)
+	}
+	return mapping.Scope.Name() == apimeta.RESTScopeNameNamespace, nil
+}
+
+// patchMetaFor returns the strategic merge patch metadata of the kind of the
+// given object, or nil if the kind is not registered with the scheme.
+func patchMetaFor(scheme *runtime.Scheme, obj *unstructured.Unstructured) strategicpatch.LookupPatchMeta {
+	if scheme == nil {
+		return nil
+	}
+	typed, err := scheme.New(obj.GroupVersionKind())
+	if err != nil {
+		return nil
+	}
+	meta, err := strategicpatch.NewPatchMetaFromStruct(typed)
+	if err != nil {
+		return nil
+	}
+	return meta
+}
+
+// matchesLive reports whether the live object holds the rendered state of the
+// given object. Only fields set in the rendered object are compared, since the
+// API server adds defaults and status. Lists are compared by merge key when
+// the patch metadata of the object is given.
+func matchesLive(obj, live *unstructured.Unstructured, meta strategicpatch.LookupPatchMeta) bool {
+	if !isSubset(obj.GetLabels(), live.GetLabels(), nil) ||
+		!isSubset(obj.GetAnnotations(), live.GetAnnotations(), nil) {
+		return false
+	}
+	for k, v := range obj.Object {
+		if driftIgnoredFields[k] {
+			continue
+		}
+		if !isFieldSubset(k, v, live.Object[k], meta) {
+			return false
+		}
+	}
+	return true
+}
+
+// isFieldSubset reports whether the desired value of the given field, of an
+// object described by the given patch metadata, is contained in its live value.
+func isFieldSubset(key string, desired, live interface{}, meta strategicpatch.LookupPatchMeta) bool {
+	if meta == nil {
+		return isSubset(desired, live, nil)
+	}
+	var (
+		sub       strategicpatch.LookupPatchMeta
+		patchMeta strategicpatch.PatchMeta
+		err       error
+	)
+	switch desired.(type) {
+	case []interface{}:
+		sub, patchMeta, err = meta.LookupPatchMetadataForSlice(key)
+	case map[string]interface{}:
+		sub, _, err = meta.LookupPatchMetadataForStruct(key)
+	default:
+		return isSubset(desired, live, nil)
+	}
+	if err != nil {
+		// e.g. maps, which have no patch metadata
+		return isSubset(desired, live, nil)
+	}
+	for _, strategy := range patchMeta.GetPatchStrategies() {
+		if strategy == "merge" {
+			return isMergedListSubset(desired.([]interface{}), live, patchMeta.GetPatchMergeKey(), sub)
+		}
+	}
+	return isSubset(desired, live, sub)
+}
+
+// isMergedListSubset reports whether the entries of the desired list are all
+// contained in the live list, which may hold extra entries. Entries are
+// matched by the given merge key or, in lists of scalars, by value.
+func isMergedListSubset(desired []interface{}, live interface{}, mergeKey string,
+	meta strategicpatch.LookupPatchMeta) bool {
+	if len(desired) == 0 {
+		return true
+	}
+	l, ok := live.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, d := range desired {
+		found := false
+		for _, e := range l {
+			if mergeKey == "" {
+				found = isSubset(d, e, nil)
+			} else if dm, ok := d.(map[string]interface{}); ok {
+				em, ok := e.(map[string]interface{})
+				found = ok && fmt.Sprint(dm[mergeKey]) == fmt.Sprint(em[mergeKey]) && isSubset(dm, em, meta)
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// isSubset reports whether the desired value is contained in the live value.
+// Maps may hold extra keys, and zero values match missing ones. Lists are
+// compared by merge key when the patch metadata of the value is given, and
+// entry by entry otherwise.
+func isSubset(desired, live interface{}, meta strategicpatch.LookupPatchMeta) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		if len(d) == 0 && live == nil {
+			return true
+		}
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range d {
+			if !isFieldSubset(k, v, l[k], meta) {
+				return false
+			}
+		}
+		return true
+	case map[string]string:
+		for k, v := range d {
+			if l, ok := live.(map[string]string); !ok || l[k] != v {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		if len(d) == 0 && live == nil {
+			return true
+		}
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(d) {
+			return false
+		}
+		for i := range d {
+			if !isSubset(d[i], l[i], meta) {
+				return false
+			}
+		}
+		return true
+	case nil:
+		return true
+	default:
+		if live == nil {
+			return isZero(desired)
+		}
+		if fmt.Sprint(desired) == fmt.Sprint(live) {
+			return true
+		}
+		// quantities may be normalized by the API server (e.g. "0.5" and "500m")
+		dq, err := resource.ParseQuantity(fmt.Sprint(desired))
+		if err != nil {
+			return false
+		}
+		lq, err := resource.ParseQuantity(fmt.Sprint(live))
+		return err == nil && dq.Cmp(lq) == 0
+	}
+}
+
+// isZero reports whether the given scalar is the zero value of its type.
+func isZero(v interface{}) bool {
+	switch x := v.(type) {
+	case string:
+		return x == ""
+	case bool:
+		return !x
+	case int64:
+		return x == 0
+	case float64:
+		return x == 0
+	default:
+		return false
+	}
+}
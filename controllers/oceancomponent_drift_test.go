@@ -0,0 +1,142 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDetectDrift(t *testing.T) {
+	manifest := `---
+# Source: foo/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+  labels:
+    app: foo
+data:
+  cpu: "0.5"
+  mode: ha
+---
+# Source: foo/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: foo
+  template:
+    metadata:
+      labels:
+        app: foo
+    spec:
+      containers:
+      - name: foo
+        image: foo:1.0
+        resources: {}
+`
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+	c := fake.NewClientBuilder().WithRESTMapper(mapper).WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "spot-system",
+				Labels:    map[string]string{"app": "foo", "extra": "bar"},
+			},
+			Data: map[string]string{"cpu": "0.5", "mode": "single"},
+		},
+	).Build()
+
+	objs, err := parseManifest(manifest)
+	assert.NoError(t, err)
+	assert.Len(t, objs, 2)
+
+	drifted, err := detectDrift(context.TODO(), c, "spot-system", objs)
+	assert.NoError(t, err)
+	if assert.Len(t, drifted, 2) {
+		assert.Equal(t, "ConfigMap spot-system/foo is modified", drifted[0].String())
+		assert.Equal(t, "Deployment spot-system/foo is missing", drifted[1].String())
+	}
+	assert.Equal(t, corev1.ConditionTrue, driftCondition(drifted, false).Status)
+
+	assert.NoError(t, correctDrift(context.TODO(), c, drifted))
+	drifted, err = detectDrift(context.TODO(), c, "spot-system", objs)
+	assert.NoError(t, err)
+	assert.Empty(t, drifted)
+	assert.Equal(t, corev1.ConditionFalse, driftCondition(drifted, false).Status)
+
+	// injected sidecars are not drift, and survive the correction of drift
+	deploy := new(appsv1.Deployment)
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "spot-system", Name: "foo"}, deploy))
+	deploy.Spec.Template.Spec.Containers = append(deploy.Spec.Template.Spec.Containers,
+		corev1.Container{Name: "istio-proxy", Image: "istio/proxyv2"})
+	assert.NoError(t, c.Update(context.TODO(), deploy))
+	drifted, err = detectDrift(context.TODO(), c, "spot-system", objs)
+	assert.NoError(t, err)
+	assert.Empty(t, drifted)
+
+	deploy.Spec.Template.Spec.Containers[0].Image = "foo:2.0"
+	assert.NoError(t, c.Update(context.TODO(), deploy))
+	drifted, err = detectDrift(context.TODO(), c, "spot-system", objs)
+	assert.NoError(t, err)
+	assert.Len(t, drifted, 1)
+	assert.NoError(t, correctDrift(context.TODO(), c, drifted))
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "spot-system", Name: "foo"}, deploy))
+	if assert.Len(t, deploy.Spec.Template.Spec.Containers, 2) {
+		assert.Equal(t, "foo:1.0", deploy.Spec.Template.Spec.Containers[0].Image)
+		assert.Equal(t, "istio-proxy", deploy.Spec.Template.Spec.Containers[1].Name)
+	}
+}
+
+func TestIsSubset(t *testing.T) {
+	t.Run("whenQuantityNormalized", func(tt *testing.T) {
+		assert.True(tt, isSubset(map[string]interface{}{"cpu": "0.5"}, map[string]interface{}{"cpu": "500m"}, nil))
+	})
+
+	t.Run("whenZeroValueOmitted", func(tt *testing.T) {
+		assert.True(tt, isSubset(map[string]interface{}{"paused": false, "volumes": []interface{}{}},
+			map[string]interface{}{}, nil))
+	})
+
+	t.Run("whenListLengthDiffers", func(tt *testing.T) {
+		assert.False(tt, isSubset([]interface{}{"a"}, []interface{}{"a", "b"}, nil))
+	})
+
+	t.Run("whenSidecarInjected", func(tt *testing.T) {
+		meta, err := strategicpatch.NewPatchMetaFromStruct(&corev1.PodSpec{})
+		assert.NoError(tt, err)
+		desired := map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "foo", "image": "foo:1.0"},
+			},
+		}
+		live := map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "istio-proxy", "image": "istio/proxyv2"},
+				map[string]interface{}{"name": "foo", "image": "foo:1.0"},
+			},
+		}
+		assert.True(tt, isSubset(desired, live, meta))
+		assert.False(tt, isSubset(desired, live, nil))
+
+		live["containers"].([]interface{})[1].(map[string]interface{})["image"] = "foo:2.0"
+		assert.False(tt, isSubset(desired, live, meta))
+	})
+}
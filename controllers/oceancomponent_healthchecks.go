@@ -0,0 +1,244 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// These are reasons of conditions set from the results of health checks.
+const (
+	reasonHealthChecksPassed  = "HealthChecksPassed"
+	reasonHealthCheckFailed   = "HealthCheckFailed"
+	reasonHealthCheckDegraded = "HealthCheckDegraded"
+)
+
+// apiServiceGVK is the kind of APIServices, which are read as unstructured
+// objects to avoid depending on the aggregator's API.
+var apiServiceGVK = schema.GroupVersionKind{
+	Group:   "apiregistration.k8s.io",
+	Version: "v1",
+	Kind:    "APIService",
+}
+
+// objectHealth describes the availability of an object checked by a health
+// check.
+type objectHealth struct {
+	name      string
+	desired   int32
+	available int32
+}
+
+// releaseHealthChecks returns health checks of the workloads of the given
+// release, i.e., the Deployments, DaemonSets and StatefulSets of its manifest.
+func releaseHealthChecks(release *installer.Release) ([]oceanv1beta1.HealthCheck, error) {
+	objs, err := parseManifest(release.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	var checks []oceanv1beta1.HealthCheck
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		if gvk.Group != appsv1.GroupName {
+			continue
+		}
+		switch kind := oceanv1beta1.HealthCheckKind(gvk.Kind); kind {
+		case oceanv1beta1.HealthCheckKindDeployment,
+			oceanv1beta1.HealthCheckKindDaemonSet,
+			oceanv1beta1.HealthCheckKindStatefulSet:
+			checks = append(checks, oceanv1beta1.HealthCheck{
+				Kind:      kind,
+				Name:      obj.GetName(),
+				Namespace: obj.GetNamespace(),
+			})
+		}
+	}
+	return checks, nil
+}
+
+// evaluateHealthChecks evaluates the given health checks, and returns the
+// Available and Degraded conditions describing their results. Checks without
+// a namespace target the given namespace.
+func evaluateHealthChecks(ctx context.Context, c client.Client, namespace string,
+	checks []oceanv1beta1.HealthCheck) ([]*oceanv1beta1.OceanComponentCondition, error) {
+	var unavailable, degraded []string
+	for _, check := range checks {
+		objs, err := getHealthCheckObjects(ctx, c, namespace, check)
+		if err != nil {
+			return nil, err
+		}
+		if len(objs) == 0 {
+			unavailable = append(unavailable, fmt.Sprintf("%s %s not found", check.Kind, healthCheckTarget(check)))
+			continue
+		}
+		for _, obj := range objs {
+			minAvailable := obj.desired
+			if check.MinAvailable != nil {
+				minAvailable = *check.MinAvailable
+			}
+			msg := fmt.Sprintf("%s %s has %d/%d available", check.Kind, obj.name, obj.available, obj.desired)
+			switch {
+			case obj.available < minAvailable:
+				unavailable = append(unavailable, msg)
+			case obj.available < obj.desired:
+				degraded = append(degraded, msg)
+			}
+		}
+	}
+
+	available := newCondition(
+		oceanv1beta1.OceanComponentConditionTypeAvailable,
+		corev1.ConditionTrue,
+		reasonHealthChecksPassed,
+		"All health checks passed",
+	)
+	if len(unavailable) > 0 {
+		available = newCondition(
+			oceanv1beta1.OceanComponentConditionTypeAvailable,
+			corev1.ConditionFalse,
+			reasonHealthCheckFailed,
+			strings.Join(unavailable, "; "),
+		)
+	}
+	degradedCond := newCondition(
+		oceanv1beta1.OceanComponentConditionTypeDegraded,
+		corev1.ConditionFalse,
+		reasonHealthChecksPassed,
+		"",
+	)
+	if len(degraded) > 0 {
+		degradedCond = newCondition(
+			oceanv1beta1.OceanComponentConditionTypeDegraded,
+			corev1.ConditionTrue,
+			reasonHealthCheckDegraded,
+			strings.Join(degraded, "; "),
+		)
+	}
+
+	return []*oceanv1beta1.OceanComponentCondition{available, degradedCond}, nil
+}
+
+// getHealthCheckObjects returns the health of the objects targeted by the
+// given health check.
+func getHealthCheckObjects(ctx context.Context, c client.Client, namespace string,
+	check oceanv1beta1.HealthCheck) ([]objectHealth, error) {
+	if check.Namespace != "" {
+		namespace = check.Namespace
+	}
+
+	var (
+		obj  client.Object
+		list client.ObjectList
+	)
+	switch check.Kind {
+	case oceanv1beta1.HealthCheckKindDeployment:
+		obj, list = new(appsv1.Deployment), new(appsv1.DeploymentList)
+	case oceanv1beta1.HealthCheckKindDaemonSet:
+		obj, list = new(appsv1.DaemonSet), new(appsv1.DaemonSetList)
+	case oceanv1beta1.HealthCheckKindStatefulSet:
+		obj, list = new(appsv1.StatefulSet), new(appsv1.StatefulSetList)
+	case oceanv1beta1.HealthCheckKindJob:
+		obj, list = new(batchv1.Job), new(batchv1.JobList)
+	case oceanv1beta1.HealthCheckKindAPIService:
+		namespace = "" // cluster-scoped
+		u, ul := new(unstructured.Unstructured), new(unstructured.UnstructuredList)
+		u.SetGroupVersionKind(apiServiceGVK)
+		ul.SetGroupVersionKind(apiServiceGVK.GroupVersion().WithKind(apiServiceGVK.Kind + "List"))
+		obj, list = u, ul
+	default:
+		return nil, fmt.Errorf("unsupported health check kind: %v", check.Kind)
+	}
+
+	var objs []runtime.Object
+	if check.Name != "" {
+		key := types.NamespacedName{Namespace: namespace, Name: check.Name}
+		if err := c.Get(ctx, key, obj); err != nil {
+			if apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		objs = []runtime.Object{obj}
+	} else {
+		selector, err := metav1.LabelSelectorAsSelector(check.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if err = c.List(ctx, list, client.InNamespace(namespace),
+			client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			if apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if objs, err = apimeta.ExtractList(list); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]objectHealth, 0, len(objs))
+	for _, o := range objs {
+		out = append(out, getObjectHealth(o))
+	}
+	return out, nil
+}
+
+// getObjectHealth returns the health of the given object.
+func getObjectHealth(obj runtime.Object) objectHealth {
+	replicas := func(r *int32) int32 {
+		if r == nil {
+			return 1
+		}
+		return *r
+	}
+
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return objectHealth{name: objectName(o), desired: replicas(o.Spec.Replicas), available: o.Status.AvailableReplicas}
+	case *appsv1.StatefulSet:
+		return objectHealth{name: objectName(o), desired: replicas(o.Spec.Replicas), available: o.Status.ReadyReplicas}
+	case *appsv1.DaemonSet:
+		return objectHealth{name: objectName(o), desired: o.Status.DesiredNumberScheduled, available: o.Status.NumberAvailable}
+	case *batchv1.Job:
+		return objectHealth{name: objectName(o), desired: replicas(o.Spec.Completions), available: o.Status.Succeeded}
+	case *unstructured.Unstructured:
+		health := objectHealth{name: o.GetName(), desired: 1}
+		conditions, _, _ := unstructured.NestedSlice(o.Object, "status", "conditions")
+		for _, c := range conditions {
+			if m, ok := c.(map[string]interface{}); ok &&
+				m["type"] == "Available" && m["status"] == string(corev1.ConditionTrue) {
+				health.available = 1
+			}
+		}
+		return health
+	default:
+		return objectHealth{name: fmt.Sprintf("%T", obj)}
+	}
+}
+
+func objectName(obj client.Object) string {
+	return client.ObjectKeyFromObject(obj).String()
+}
+
+func healthCheckTarget(check oceanv1beta1.HealthCheck) string {
+	if check.Name != "" {
+		return check.Name
+	}
+	return metav1.FormatLabelSelector(check.Selector)
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEvaluateHealthChecks(t *testing.T) {
+	replicas := int32(2)
+	client := fake.NewClientBuilder().WithObjects(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "spot-system"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 2},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "kube-system", Labels: map[string]string{"app": "bar"}},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberAvailable: 2},
+		},
+	).Build()
+
+	deployment := oceanv1beta1.HealthCheck{
+		Kind: oceanv1beta1.HealthCheckKindDeployment,
+		Name: "foo",
+	}
+	daemonSet := oceanv1beta1.HealthCheck{
+		Kind:      oceanv1beta1.HealthCheckKindDaemonSet,
+		Namespace: "kube-system",
+		Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "bar"}},
+	}
+	minAvailable := func(check oceanv1beta1.HealthCheck, n int32) oceanv1beta1.HealthCheck {
+		check.MinAvailable = &n
+		return check
+	}
+
+	tests := []struct {
+		name      string
+		checks    []oceanv1beta1.HealthCheck
+		available corev1.ConditionStatus
+		degraded  corev1.ConditionStatus
+	}{
+		{
+			name:      "whenAvailable",
+			checks:    []oceanv1beta1.HealthCheck{deployment},
+			available: corev1.ConditionTrue,
+			degraded:  corev1.ConditionFalse,
+		},
+		{
+			name:      "whenUnavailable",
+			checks:    []oceanv1beta1.HealthCheck{deployment, daemonSet},
+			available: corev1.ConditionFalse,
+			degraded:  corev1.ConditionFalse,
+		},
+		{
+			name:      "whenDegraded",
+			checks:    []oceanv1beta1.HealthCheck{deployment, minAvailable(daemonSet, 1)},
+			available: corev1.ConditionTrue,
+			degraded:  corev1.ConditionTrue,
+		},
+		{
+			name: "whenNotFound",
+			checks: []oceanv1beta1.HealthCheck{{
+				Kind: oceanv1beta1.HealthCheckKindStatefulSet,
+				Name: "baz",
+			}},
+			available: corev1.ConditionFalse,
+			degraded:  corev1.ConditionFalse,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			conditions, err := evaluateHealthChecks(context.TODO(), client, "spot-system", test.checks)
+			assert.NoError(tt, err)
+			assert.Len(tt, conditions, 2)
+			assert.Equal(tt, oceanv1beta1.OceanComponentConditionTypeAvailable, conditions[0].Type)
+			assert.Equal(tt, test.available, conditions[0].Status)
+			assert.Equal(tt, oceanv1beta1.OceanComponentConditionTypeDegraded, conditions[1].Type)
+			assert.Equal(tt, test.degraded, conditions[1].Status)
+		})
+	}
+
+	t.Run("whenKindNotServed", func(tt *testing.T) {
+		conditions, err := evaluateHealthChecks(context.TODO(), noKindMatchClient{client}, "spot-system",
+			[]oceanv1beta1.HealthCheck{{
+				Kind: oceanv1beta1.HealthCheckKindAPIService,
+				Name: "v1beta1.metrics.k8s.io",
+			}})
+		assert.NoError(tt, err)
+		assert.Equal(tt, corev1.ConditionFalse, conditions[0].Status)
+	})
+}
+
+// noKindMatchClient is a client of an API server that serves no kinds.
+type noKindMatchClient struct {
+	client.Client
+}
+
+func (noKindMatchClient) Get(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return &apimeta.NoKindMatchError{GroupKind: gvk.GroupKind(), SearchedVersions: []string{gvk.Version}}
+}
+
+func TestReleaseHealthChecks(t *testing.T) {
+	release := &installer.Release{Manifest: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: metrics-server-config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: metrics-server
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: node-exporter
+  namespace: monitoring
+`}
+	checks, err := releaseHealthChecks(release)
+	assert.NoError(t, err)
+	assert.Equal(t, []oceanv1beta1.HealthCheck{
+		{Kind: oceanv1beta1.HealthCheckKindDeployment, Name: "metrics-server"},
+		{Kind: oceanv1beta1.HealthCheckKindDaemonSet, Name: "node-exporter", Namespace: "monitoring"},
+	}, checks)
+}
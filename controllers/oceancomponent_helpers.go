@@ -4,13 +4,18 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 
-	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
-	"github.com/spotinst/ocean-operator/pkg/tide"
-	appsv1 "k8s.io/api/apps/v1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/spotinst/ocean-operator/pkg/log"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -18,10 +23,10 @@ import (
 )
 
 // newCondition creates a new OceanComponent condition.
-func newCondition(condType oceanv1alpha1.OceanComponentConditionType,
+func newCondition(condType oceanv1beta1.OceanComponentConditionType,
 	status corev1.ConditionStatus, reason, message string,
-) *oceanv1alpha1.OceanComponentCondition {
-	return &oceanv1alpha1.OceanComponentCondition{
+) *oceanv1beta1.OceanComponentCondition {
+	return &oceanv1beta1.OceanComponentCondition{
 		Type:               condType,
 		Status:             status,
 		Reason:             reason,
@@ -32,15 +37,15 @@ func newCondition(condType oceanv1alpha1.OceanComponentConditionType,
 }
 
 // newConditionf returns a new OceanComponent condition with arguments.
-func newConditionf(condType oceanv1alpha1.OceanComponentConditionType,
+func newConditionf(condType oceanv1beta1.OceanComponentConditionType,
 	status corev1.ConditionStatus, reason, message string, args ...interface{},
-) *oceanv1alpha1.OceanComponentCondition {
+) *oceanv1beta1.OceanComponentCondition {
 	return newCondition(condType, status, reason, fmt.Sprintf(message, args...))
 }
 
 // hasCondition returns true if the given status has the given condition.
-func hasCondition(status *oceanv1alpha1.OceanComponentStatus,
-	condition oceanv1alpha1.OceanComponentCondition) bool {
+func hasCondition(status *oceanv1beta1.OceanComponentStatus,
+	condition oceanv1beta1.OceanComponentCondition) bool {
 	c := status.Conditions
 	for _, e := range c {
 		if e.Type == condition.Type {
@@ -53,12 +58,13 @@ func hasCondition(status *oceanv1alpha1.OceanComponentStatus,
 // setCondition updates the OceanComponent to include the provided
 // condition. If the condition that we are about to add already exists and has
 // the same status and reason then we are not going to update.
-func setCondition(status *oceanv1alpha1.OceanComponentStatus,
-	condition oceanv1alpha1.OceanComponentCondition) bool {
+func setCondition(status *oceanv1beta1.OceanComponentStatus,
+	condition oceanv1beta1.OceanComponentCondition) bool {
 	currentCond := getCondition(*status, condition.Type)
 	if currentCond != nil &&
 		currentCond.Status == condition.Status &&
-		currentCond.Reason == condition.Reason {
+		currentCond.Reason == condition.Reason &&
+		currentCond.ObservedGeneration == condition.ObservedGeneration {
 		return false
 	}
 	// Do not update lastTransitionTime if the status of the condition doesn't change.
@@ -71,15 +77,15 @@ func setCondition(status *oceanv1alpha1.OceanComponentStatus,
 }
 
 // removeCondition removes the condition with the provided type.
-func removeCondition(status *oceanv1alpha1.OceanComponentStatus,
-	condType oceanv1alpha1.OceanComponentConditionType) {
+func removeCondition(status *oceanv1beta1.OceanComponentStatus,
+	condType oceanv1beta1.OceanComponentConditionType) {
 	status.Conditions = filterOutCondition(status.Conditions, condType)
 }
 
 // getCurrentCondition returns the condition with the most recent
 // update.
 func getCurrentCondition(
-	status oceanv1alpha1.OceanComponentStatus) *oceanv1alpha1.OceanComponentCondition {
+	status oceanv1beta1.OceanComponentStatus) *oceanv1beta1.OceanComponentCondition {
 	if len(status.Conditions) == 0 {
 		return nil
 	}
@@ -87,7 +93,7 @@ func getCurrentCondition(
 	return &status.Conditions[0]
 }
 
-func sortMostRecent(status *oceanv1alpha1.OceanComponentStatus) {
+func sortMostRecent(status *oceanv1beta1.OceanComponentStatus) {
 	c := status.Conditions
 	sort.Slice(c, func(i int, j int) bool {
 		return c[i].LastUpdateTime.Time.After(c[j].LastUpdateTime.Time)
@@ -96,8 +102,8 @@ func sortMostRecent(status *oceanv1alpha1.OceanComponentStatus) {
 }
 
 // getCondition returns the condition with the provided type.
-func getCondition(status oceanv1alpha1.OceanComponentStatus,
-	condType oceanv1alpha1.OceanComponentConditionType) *oceanv1alpha1.OceanComponentCondition {
+func getCondition(status oceanv1beta1.OceanComponentStatus,
+	condType oceanv1beta1.OceanComponentConditionType) *oceanv1beta1.OceanComponentCondition {
 	for i := range status.Conditions {
 		c := status.Conditions[i]
 		if c.Type == condType {
@@ -109,9 +115,9 @@ func getCondition(status oceanv1alpha1.OceanComponentStatus,
 
 // filterOutCondition returns a new slice of conditions without conditions with
 // the provided type.
-func filterOutCondition(conditions []oceanv1alpha1.OceanComponentCondition,
-	condType oceanv1alpha1.OceanComponentConditionType) []oceanv1alpha1.OceanComponentCondition {
-	var newConditions []oceanv1alpha1.OceanComponentCondition
+func filterOutCondition(conditions []oceanv1beta1.OceanComponentCondition,
+	condType oceanv1beta1.OceanComponentConditionType) []oceanv1beta1.OceanComponentCondition {
+	var newConditions []oceanv1beta1.OceanComponentCondition
 	for _, condition := range conditions {
 		if condition.Type == condType {
 			continue
@@ -121,55 +127,245 @@ func filterOutCondition(conditions []oceanv1alpha1.OceanComponentCondition,
 	return newConditions
 }
 
-func getDeploymentConditions(ctx context.Context, client client.Client,
-	objName types.NamespacedName) ([]*oceanv1alpha1.OceanComponentCondition, error) {
-	conditions := make([]*oceanv1alpha1.OceanComponentCondition, 0)
-
-	deployment := new(appsv1.Deployment)
-	if err := client.Get(ctx, objName, deployment); err != nil {
-		if apierrors.IsNotFound(err) {
-			conditions = append(conditions, newCondition(
-				oceanv1alpha1.OceanComponentConditionTypeAvailable,
-				corev1.ConditionFalse,
-				"DeploymentAbsent",
-				"Deployment does not exist",
-			))
-			return conditions, nil // enough, return
-		} else {
-			return nil, err
+// ensureNamespace creates the given namespace, unless it already exists.
+func ensureNamespace(ctx context.Context, c client.Client, logger log.Logger, namespace string) error {
+	ns := new(corev1.Namespace)
+	key := types.NamespacedName{Name: namespace}
+	logger.V(1).Info("checking existence", "namespace", namespace)
+	err := c.Get(ctx, key, ns)
+	if apierrors.IsNotFound(err) {
+		ns.Name = namespace
+		logger.Info("creating", "namespace", namespace)
+		return c.Create(ctx, ns)
+	}
+	return err
+}
+
+// setReadyCondition sets the Ready condition, summarising the Failing,
+// Progressing, Degraded and Available conditions, in that order of precedence.
+func setReadyCondition(status *oceanv1beta1.OceanComponentStatus, generation int64) bool {
+	ready := newCondition(
+		oceanv1beta1.OceanComponentConditionTypeReady,
+		corev1.ConditionUnknown,
+		installer.ReleaseStatusUnknown.String(),
+		"Component status is unknown",
+	)
+	ready.ObservedGeneration = generation
+
+	available := getCondition(*status, oceanv1beta1.OceanComponentConditionTypeAvailable)
+	for _, condType := range []oceanv1beta1.OceanComponentConditionType{
+		oceanv1beta1.OceanComponentConditionTypeFailure,
+		oceanv1beta1.OceanComponentConditionTypeProgressing,
+		oceanv1beta1.OceanComponentConditionTypeDegraded,
+	} {
+		if c := getCondition(*status, condType); c != nil && c.Status == corev1.ConditionTrue {
+			available = c
+			break
+		}
+	}
+	if available != nil {
+		ready.Status = corev1.ConditionFalse
+		if available.Type == oceanv1beta1.OceanComponentConditionTypeAvailable {
+			ready.Status = available.Status
 		}
+		ready.Reason = available.Reason
+		ready.Message = available.Message
+	}
+
+	return setCondition(status, *ready)
+}
+
+// isSuspended reports whether the reconciliation of the given component is
+// suspended and, if so, by what.
+func isSuspended(comp *oceanv1beta1.OceanComponent) (bool, string) {
+	if comp.Spec.Suspend {
+		return true, "spec.suspend"
+	}
+	if v, ok := comp.Annotations[oceanv1beta1.SuspendAnnotation]; ok {
+		if suspend, _ := strconv.ParseBool(v); suspend {
+			return true, fmt.Sprintf("annotation %s", oceanv1beta1.SuspendAnnotation)
+		}
+	}
+	return false, ""
+}
+
+// failureReason returns the reason of the Failing condition set when an
+// operation fails with the given error, which is the given reason unless the
+// error has a more specific one.
+func failureReason(err error, reason string) string {
+	if installer.IsVerificationFailed(err) {
+		return reasonVerificationFailed
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return reasonOperationTimedOut
+	}
+	return reason
+}
+
+// setReleaseStatus sets the status fields describing the given release. A nil
+// release clears them.
+func setReleaseStatus(status *oceanv1beta1.OceanComponentStatus, release *installer.Release) {
+	if release == nil {
+		status.Version = ""
+		status.AppVersion = ""
+		status.Revision = 0
+		status.ResolvedVersion = ""
+		return
 	}
+	status.Version = release.Version
+	status.AppVersion = release.AppVersion
+	status.Revision = release.Revision
+}
 
-	if deployment.Status.AvailableReplicas == 0 {
-		conditions = append(conditions, newCondition(
-			oceanv1alpha1.OceanComponentConditionTypeAvailable,
-			corev1.ConditionFalse,
-			"DeploymentUnavailable",
-			"No pods are available",
-		))
-	} else {
-		conditions = append(conditions, newCondition(
-			oceanv1alpha1.OceanComponentConditionTypeAvailable,
-			corev1.ConditionTrue,
-			"DeploymentAvailable",
-			"Pods are available",
-		))
+// setOperationStatus sets the status fields describing an operation that
+// resulted in the given release and values. A nil release indicates that the
+// component has been uninstalled.
+func setOperationStatus(status *oceanv1beta1.OceanComponentStatus,
+	release *installer.Release, values *apiextensionsv1.JSON) {
+	now := metav1.Now()
+	status.LastOperationTime = &now
+	setReleaseStatus(status, release)
+	if release == nil {
+		status.ValuesHash = ""
+		return
 	}
+	status.ValuesHash = hashValues(values)
+}
 
-	return conditions, nil
+// releaseValues returns the values of the given release encoded as JSON, or
+// nil if it has none.
+func releaseValues(release *installer.Release) *apiextensionsv1.JSON {
+	if release == nil || len(release.Values) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(release.Values)
+	if err != nil {
+		return nil
+	}
+	return &apiextensionsv1.JSON{Raw: raw}
 }
 
-func getOceanControllerConditions(ctx context.Context, client client.Client,
-	objName types.NamespacedName) ([]*oceanv1alpha1.OceanComponentCondition, error) {
-	// ocean-controller's deployment name differs from its component name
-	objName = types.NamespacedName{
-		Namespace: metav1.NamespaceSystem,
-		Name:      tide.LegacyOceanControllerDeployment,
+// lastDeployedRevision returns the newest revision in the given history, older
+// than the given revision, that was successfully deployed, or nil if none was.
+func lastDeployedRevision(history []*installer.Release, before int) *installer.Release {
+	var last *installer.Release
+	for _, rel := range history {
+		if rel.Revision >= before {
+			continue
+		}
+		if rel.Status != installer.ReleaseStatusDeployed && rel.Status != installer.ReleaseStatusSuperseded {
+			continue
+		}
+		if last == nil || rel.Revision > last.Revision {
+			last = rel
+		}
 	}
-	return getDeploymentConditions(ctx, client, objName)
+	return last
 }
 
-func getMetricsServerConditions(ctx context.Context, client client.Client,
-	objName types.NamespacedName) ([]*oceanv1alpha1.OceanComponentCondition, error) {
-	return getDeploymentConditions(ctx, client, objName)
+// hashValues returns the SHA-256 hash of the given values. Values are decoded
+// and encoded again, so the hash does not depend on key order or formatting.
+func hashValues(values *apiextensionsv1.JSON) string {
+	data := []byte("{}")
+	if values != nil && len(values.Raw) > 0 {
+		var v interface{}
+		if err := json.Unmarshal(values.Raw, &v); err == nil {
+			data, _ = json.Marshal(v)
+		} else {
+			data = values.Raw
+		}
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// referencesIndexKey is the field index of the Secrets and ConfigMaps
+// referenced by a component: its valuesFrom, the credentials of its chart
+// repository, the keyring that verifies its chart and the sources of its
+// chart, manifest or kustomization.
+const referencesIndexKey = ".spec.references"
+
+// referencesIndexValue returns the field index value of a referenced object.
+func referencesIndexValue(kind oceanv1beta1.ValuesReferenceKind, name string) string {
+	return kind.String() + "/" + name
+}
+
+// indexReferences is a client.IndexerFunc that indexes components by the
+// Secrets and ConfigMaps they reference.
+func indexReferences(obj client.Object) []string {
+	comp, ok := obj.(*oceanv1beta1.OceanComponent)
+	if !ok {
+		return nil
+	}
+	var out []string
+	ref := func(kind oceanv1beta1.ValuesReferenceKind, name string) {
+		if name != "" {
+			out = append(out, referencesIndexValue(kind, name))
+		}
+	}
+	for _, r := range comp.Spec.ValuesFrom {
+		ref(r.Kind, r.Name)
+	}
+	if r := comp.Spec.Repository; r != nil && r.SecretRef != nil {
+		ref(oceanv1beta1.ValuesReferenceKindSecret, r.SecretRef.Name)
+	}
+	if v := comp.Spec.Verify; v != nil {
+		ref(oceanv1beta1.ValuesReferenceKindSecret, v.KeyringSecretRef.Name)
+	}
+	if s := comp.Spec.Source; s != nil {
+		if s.Secret != nil {
+			ref(oceanv1beta1.ValuesReferenceKindSecret, s.Secret.Name)
+		}
+		if s.ConfigMap != nil {
+			ref(oceanv1beta1.ValuesReferenceKindConfigMap, s.ConfigMap.Name)
+		}
+	}
+	if m := comp.Spec.Manifest; m != nil && m.ConfigMap != nil {
+		ref(oceanv1beta1.ValuesReferenceKindConfigMap, m.ConfigMap.Name)
+	}
+	if k := comp.Spec.Kustomize; k != nil && k.ConfigMap != nil {
+		ref(oceanv1beta1.ValuesReferenceKindConfigMap, k.ConfigMap.Name)
+	}
+	return out
+}
+
+// dependsOnIndexKey is the field index of the components a component depends on.
+const dependsOnIndexKey = ".spec.dependsOn"
+
+// indexDependsOn is a client.IndexerFunc that indexes components by the names
+// of the components they depend on.
+func indexDependsOn(obj client.Object) []string {
+	comp, ok := obj.(*oceanv1beta1.OceanComponent)
+	if !ok {
+		return nil
+	}
+	return comp.Spec.DependsOn
+}
+
+// findDependencyCycle returns the path of a dependency cycle starting and
+// ending at the given component, or nil if there is none. Dependencies of a
+// component are returned by the given function.
+func findDependencyCycle(name string, dependsOn func(name string) ([]string, error)) ([]string, error) {
+	visited := make(map[string]struct{})
+	var visit func(path []string) ([]string, error)
+	visit = func(path []string) ([]string, error) {
+		deps, err := dependsOn(path[len(path)-1])
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range deps {
+			if dep == name {
+				return append(path[:len(path):len(path)], dep), nil
+			}
+			if _, ok := visited[dep]; ok {
+				continue
+			}
+			visited[dep] = struct{}{}
+			cycle, err := visit(append(path[:len(path):len(path)], dep))
+			if err != nil || cycle != nil {
+				return cycle, err
+			}
+		}
+		return nil, nil
+	}
+	return visit([]string{name})
 }
@@ -0,0 +1,191 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestSetReadyCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []*oceanv1beta1.OceanComponentCondition
+		status     corev1.ConditionStatus
+		reason     string
+	}{
+		{
+			name:   "whenNoConditions",
+			status: corev1.ConditionUnknown,
+			reason: "Unknown",
+		},
+		{
+			name: "whenAvailable",
+			conditions: []*oceanv1beta1.OceanComponentCondition{
+				newCondition(oceanv1beta1.OceanComponentConditionTypeAvailable, corev1.ConditionTrue, "Installed", ""),
+				newCondition(oceanv1beta1.OceanComponentConditionTypeProgressing, corev1.ConditionFalse, "Installed", ""),
+			},
+			status: corev1.ConditionTrue,
+			reason: "Installed",
+		},
+		{
+			name: "whenProgressing",
+			conditions: []*oceanv1beta1.OceanComponentCondition{
+				newCondition(oceanv1beta1.OceanComponentConditionTypeAvailable, corev1.ConditionTrue, "Installed", ""),
+				newCondition(oceanv1beta1.OceanComponentConditionTypeProgressing, corev1.ConditionTrue, "Upgrading", ""),
+			},
+			status: corev1.ConditionFalse,
+			reason: "Upgrading",
+		},
+		{
+			name: "whenFailing",
+			conditions: []*oceanv1beta1.OceanComponentCondition{
+				newCondition(oceanv1beta1.OceanComponentConditionTypeProgressing, corev1.ConditionTrue, "Upgrading", ""),
+				newCondition(oceanv1beta1.OceanComponentConditionTypeFailure, corev1.ConditionTrue, "UpgradeFailed", ""),
+			},
+			status: corev1.ConditionFalse,
+			reason: "UpgradeFailed",
+		},
+		{
+			name: "whenUnavailable",
+			conditions: []*oceanv1beta1.OceanComponentCondition{
+				newCondition(oceanv1beta1.OceanComponentConditionTypeAvailable, corev1.ConditionFalse, "DeploymentUnavailable", ""),
+			},
+			status: corev1.ConditionFalse,
+			reason: "DeploymentUnavailable",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(tt *testing.T) {
+			status := new(oceanv1beta1.OceanComponentStatus)
+			for _, c := range tc.conditions {
+				setCondition(status, *c)
+			}
+			setReadyCondition(status, 3)
+
+			ready := getCondition(*status, oceanv1beta1.OceanComponentConditionTypeReady)
+			assert.NotNil(tt, ready)
+			assert.Equal(tt, tc.status, ready.Status)
+			assert.Equal(tt, tc.reason, ready.Reason)
+			assert.Equal(tt, int64(3), ready.ObservedGeneration)
+		})
+	}
+}
+
+func TestHashValues(t *testing.T) {
+	a := hashValues(&apiextensionsv1.JSON{Raw: []byte(`{"a": 1, "b": {"c": true}}`)})
+	b := hashValues(&apiextensionsv1.JSON{Raw: []byte(`{"b":{"c":true},"a":1}`)})
+	assert.Equal(t, a, b)
+	assert.Equal(t, hashValues(nil), hashValues(&apiextensionsv1.JSON{Raw: []byte(`{}`)}))
+	assert.NotEqual(t, a, hashValues(nil))
+}
+
+func TestIndexReferences(t *testing.T) {
+	comp := &oceanv1beta1.OceanComponent{Spec: oceanv1beta1.OceanComponentSpec{
+		ValuesFrom: []oceanv1beta1.ValuesReference{
+			{Kind: oceanv1beta1.ValuesReferenceKindConfigMap, Name: "values"},
+		},
+		Repository: &oceanv1beta1.RepositoryOptions{
+			SecretRef: &corev1.LocalObjectReference{Name: "repository"},
+		},
+		Verify: &oceanv1beta1.ChartVerification{
+			KeyringSecretRef: corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "keyring"},
+			},
+		},
+		Source: &oceanv1beta1.ChartSource{
+			Secret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "chart"},
+			},
+		},
+	}}
+	assert.Equal(t, []string{"ConfigMap/values", "Secret/repository", "Secret/keyring", "Secret/chart"},
+		indexReferences(comp))
+}
+
+func TestFindDependencyCycle(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"c"},
+		"c": {"d"},
+		"d": {"b"},
+	}
+	dependsOn := func(name string) ([]string, error) { return graph[name], nil }
+
+	t.Run("whenNoCycle", func(tt *testing.T) {
+		cycle, err := findDependencyCycle("a", dependsOn)
+		assert.NoError(tt, err)
+		assert.Nil(tt, cycle)
+	})
+
+	t.Run("whenCycle", func(tt *testing.T) {
+		cycle, err := findDependencyCycle("b", dependsOn)
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{"b", "c", "d", "b"}, cycle)
+	})
+}
+
+func TestIsSuspended(t *testing.T) {
+	comp := new(oceanv1beta1.OceanComponent)
+
+	suspended, _ := isSuspended(comp)
+	assert.False(t, suspended)
+
+	comp.Spec.Suspend = true
+	suspended, source := isSuspended(comp)
+	assert.True(t, suspended)
+	assert.Equal(t, "spec.suspend", source)
+
+	comp.Spec.Suspend = false
+	comp.Annotations = map[string]string{oceanv1beta1.SuspendAnnotation: "true"}
+	suspended, source = isSuspended(comp)
+	assert.True(t, suspended)
+	assert.Equal(t, "annotation ocean.spot.io/suspend", source)
+
+	comp.Annotations[oceanv1beta1.SuspendAnnotation] = "false"
+	suspended, _ = isSuspended(comp)
+	assert.False(t, suspended)
+}
+
+func TestLastDeployedRevision(t *testing.T) {
+	history := []*installer.Release{
+		{Revision: 1, Status: installer.ReleaseStatusSuperseded},
+		{Revision: 2, Status: installer.ReleaseStatusDeployed},
+		{Revision: 3, Status: installer.ReleaseStatusFailed},
+		{Revision: 4, Status: installer.ReleaseStatusFailed},
+	}
+
+	assert.Equal(t, 2, lastDeployedRevision(history, 4).Revision)
+	assert.Equal(t, 1, lastDeployedRevision(history, 2).Revision)
+	assert.Nil(t, lastDeployedRevision(history, 1))
+	assert.Nil(t, lastDeployedRevision(nil, 1))
+}
+
+func TestPendingTimedOut(t *testing.T) {
+	now := time.Now()
+	r := &OceanComponentReconciler{OperationTimeout: 5 * time.Minute}
+
+	assert.False(t, r.pendingTimedOut(&installer.Release{LastDeployed: now.Add(-time.Minute)}, now))
+	assert.True(t, r.pendingTimedOut(&installer.Release{LastDeployed: now.Add(-10 * time.Minute)}, now))
+	assert.False(t, r.pendingTimedOut(&installer.Release{}, now))
+	assert.False(t, new(OceanComponentReconciler).pendingTimedOut(
+		&installer.Release{LastDeployed: now.Add(-10 * time.Minute)}, now))
+}
+
+func TestFailureReason(t *testing.T) {
+	assert.Equal(t, "InstallFailed", failureReason(errors.New("boom"), "InstallFailed"))
+	assert.Equal(t, reasonVerificationFailed,
+		failureReason(fmt.Errorf("%w: bad digest", installer.ErrVerificationFailed), "InstallFailed"))
+	assert.Equal(t, reasonOperationTimedOut,
+		failureReason(fmt.Errorf("installation error: %w", context.DeadlineExceeded), "InstallFailed"))
+}
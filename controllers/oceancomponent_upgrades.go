@@ -0,0 +1,165 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// These are reasons of conditions set while gating upgrades.
+const (
+	reasonUpgradeNotPermitted      = "UpgradeNotPermitted"
+	reasonOutsideMaintenanceWindow = "OutsideMaintenanceWindow"
+	reasonNoUpgradePending         = "NoUpgradePending"
+	reasonRolledBack               = "RolledBack"
+)
+
+// maintenanceWindowLookaheadDays is the number of days searched for the next
+// opening of a maintenance window.
+const maintenanceWindowLookaheadDays = 8
+
+// heldUpgrade returns the UpgradePending condition describing why the upgrade
+// of the release to the desired component is held back, and how long to wait
+// before checking again. A nil condition means the upgrade may run now.
+func heldUpgrade(current, desired *oceanv1beta1.OceanComponent,
+	release *installer.Release, now time.Time) (*oceanv1beta1.OceanComponentCondition, time.Duration, error) {
+	// a failed upgrade is not retried until the component changes
+	if rb := current.Status.LastRollback; rb != nil && rb.ObservedGeneration == current.Generation &&
+		rb.FailedVersion == desired.Spec.Version && rb.FailedValuesHash == hashValues(desired.Spec.Values) {
+		return newCondition(
+			oceanv1beta1.OceanComponentConditionTypeUpgradePending,
+			corev1.ConditionTrue,
+			reasonRolledBack,
+			fmt.Sprintf("Upgrade to version %s failed and was rolled back to revision %d",
+				desired.Spec.Version, rb.ToRevision),
+		), 0, nil
+	}
+
+	// the version is exact when resolving it left it unchanged
+	explicit := current.Spec.Version == desired.Spec.Version
+	if !explicit && !upgradePermitted(desired.Spec.UpgradePolicy, release.Version, desired.Spec.Version) {
+		return newCondition(
+			oceanv1beta1.OceanComponentConditionTypeUpgradePending,
+			corev1.ConditionTrue,
+			reasonUpgradeNotPermitted,
+			fmt.Sprintf("Upgrade to version %s is not permitted by upgrade policy %s",
+				desired.Spec.Version, desired.Spec.UpgradePolicy),
+		), 0, nil
+	}
+
+	open, next, err := inMaintenanceWindow(desired.Spec.MaintenanceWindows, now)
+	if err != nil || open {
+		return nil, 0, err
+	}
+	return newCondition(
+		oceanv1beta1.OceanComponentConditionTypeUpgradePending,
+		corev1.ConditionTrue,
+		reasonOutsideMaintenanceWindow,
+		fmt.Sprintf("Upgrade to version %s is pending until the next maintenance window at %s",
+			desired.Spec.Version, next.Format(time.RFC3339)),
+	), next.Sub(now), nil
+}
+
+// newRollbackStatus returns the status of the rollback of the failed release
+// of the component to the target release. The failed values are those of the
+// desired component, which the release values differ from when values are
+// reused or reset, so that heldUpgrade recognizes the failed upgrade.
+func newRollbackStatus(comp, desired *oceanv1beta1.OceanComponent,
+	failed, target *installer.Release, now metav1.Time) *oceanv1beta1.RollbackStatus {
+	return &oceanv1beta1.RollbackStatus{
+		Time:               now,
+		FromRevision:       failed.Revision,
+		ToRevision:         target.Revision,
+		FailedVersion:      failed.Version,
+		FailedValuesHash:   hashValues(desired.Spec.Values),
+		ObservedGeneration: comp.Generation,
+	}
+}
+
+// upgradePermitted reports whether the given upgrade policy permits changing
+// the installed version to the target version automatically.
+func upgradePermitted(policy oceanv1beta1.UpgradePolicy, installed, target string) bool {
+	if policy == "" || installed == target {
+		return true
+	}
+	if policy == oceanv1beta1.UpgradePolicyManual {
+		return false
+	}
+
+	from, err := semver.NewVersion(installed)
+	if err != nil {
+		return false
+	}
+	to, err := semver.NewVersion(target)
+	if err != nil || to.LessThan(from) || to.Major() != from.Major() {
+		return false
+	}
+	return policy == oceanv1beta1.UpgradePolicyMinor || to.Minor() == from.Minor()
+}
+
+// inMaintenanceWindow reports whether the given time falls within any of the
+// given maintenance windows and, when it does not, the time the next one
+// opens. No windows means always open.
+func inMaintenanceWindow(windows []oceanv1beta1.MaintenanceWindow, t time.Time) (bool, time.Time, error) {
+	if len(windows) == 0 {
+		return true, t, nil
+	}
+
+	var next time.Time
+	for _, window := range windows {
+		loc, err := time.LoadLocation(window.TimeZone)
+		if err != nil {
+			return false, next, fmt.Errorf("invalid maintenance window time zone %q: %w", window.TimeZone, err)
+		}
+		start, err := time.Parse(oceanv1beta1.TimeOfDayLayout, window.Start)
+		if err != nil {
+			return false, next, fmt.Errorf("invalid maintenance window start %q: %w", window.Start, err)
+		}
+		end, err := time.Parse(oceanv1beta1.TimeOfDayLayout, window.End)
+		if err != nil {
+			return false, next, fmt.Errorf("invalid maintenance window end %q: %w", window.End, err)
+		}
+
+		local := t.In(loc)
+		// start from the previous day, whose window may still be open
+		for i := -1; i < maintenanceWindowLookaheadDays; i++ {
+			day := time.Date(local.Year(), local.Month(), local.Day()+i, 0, 0, 0, 0, loc)
+			if !windowOpensOn(window, day.Weekday()) {
+				continue
+			}
+			opens := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+			closes := time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+			if !closes.After(opens) {
+				closes = closes.AddDate(0, 0, 1)
+			}
+			if !local.Before(opens) && local.Before(closes) {
+				return true, t, nil
+			}
+			if opens.After(local) && (next.IsZero() || opens.Before(next)) {
+				next = opens
+			}
+		}
+	}
+	return false, next, nil
+}
+
+// windowOpensOn reports whether the given maintenance window opens on the
+// given day of the week.
+func windowOpensOn(window oceanv1beta1.MaintenanceWindow, day time.Weekday) bool {
+	if len(window.Days) == 0 {
+		return true
+	}
+	for _, d := range window.Days {
+		if d.String() == day.String() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpgradePermitted(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    oceanv1beta1.UpgradePolicy
+		installed string
+		target    string
+		permitted bool
+	}{
+		{name: "whenNoPolicy", installed: "1.0.0", target: "2.0.0", permitted: true},
+		{name: "whenManual", policy: oceanv1beta1.UpgradePolicyManual, installed: "1.0.0", target: "1.0.1"},
+		{name: "whenManualSameVersion", policy: oceanv1beta1.UpgradePolicyManual, installed: "1.0.0", target: "1.0.0", permitted: true},
+		{name: "whenPatch", policy: oceanv1beta1.UpgradePolicyPatch, installed: "1.0.0", target: "1.0.1", permitted: true},
+		{name: "whenPatchMinor", policy: oceanv1beta1.UpgradePolicyPatch, installed: "1.0.0", target: "1.1.0"},
+		{name: "whenMinor", policy: oceanv1beta1.UpgradePolicyMinor, installed: "1.0.0", target: "1.1.0", permitted: true},
+		{name: "whenMinorMajor", policy: oceanv1beta1.UpgradePolicyMinor, installed: "1.0.0", target: "2.0.0"},
+		{name: "whenDowngrade", policy: oceanv1beta1.UpgradePolicyMinor, installed: "1.1.0", target: "1.0.0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.permitted, upgradePermitted(test.policy, test.installed, test.target))
+		})
+	}
+}
+
+func TestInMaintenanceWindow(t *testing.T) {
+	// Saturday, 22:30 UTC
+	now := time.Date(2021, time.October, 16, 22, 30, 0, 0, time.UTC)
+
+	t.Run("whenNoWindows", func(tt *testing.T) {
+		open, _, err := inMaintenanceWindow(nil, now)
+		assert.NoError(tt, err)
+		assert.True(tt, open)
+	})
+
+	t.Run("whenOpenPastMidnight", func(tt *testing.T) {
+		open, _, err := inMaintenanceWindow([]oceanv1beta1.MaintenanceWindow{
+			{Days: []oceanv1beta1.Weekday{"Saturday"}, Start: "22:00", End: "02:00"},
+		}, now.Add(3*time.Hour))
+		assert.NoError(tt, err)
+		assert.True(tt, open)
+	})
+
+	t.Run("whenClosed", func(tt *testing.T) {
+		open, next, err := inMaintenanceWindow([]oceanv1beta1.MaintenanceWindow{
+			{Days: []oceanv1beta1.Weekday{"Sunday"}, Start: "01:00", End: "05:00"},
+			{Days: []oceanv1beta1.Weekday{"Monday"}, Start: "01:00", End: "05:00"},
+		}, now)
+		assert.NoError(tt, err)
+		assert.False(tt, open)
+		assert.Equal(tt, time.Date(2021, time.October, 17, 1, 0, 0, 0, time.UTC), next.UTC())
+	})
+
+	t.Run("whenTimeZone", func(tt *testing.T) {
+		// 22:30 UTC is 00:30 in Berlin (CEST) on Sunday
+		open, _, err := inMaintenanceWindow([]oceanv1beta1.MaintenanceWindow{
+			{Days: []oceanv1beta1.Weekday{"Sunday"}, Start: "00:00", End: "06:00", TimeZone: "Europe/Berlin"},
+		}, now)
+		assert.NoError(tt, err)
+		assert.True(tt, open)
+	})
+}
+
+func TestHeldUpgrade(t *testing.T) {
+	current := &oceanv1beta1.OceanComponent{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec: oceanv1beta1.OceanComponentSpec{
+			Name:    oceanv1beta1.MetricsServerComponentName,
+			Version: "2.8.9",
+			Values:  &apiextensionsv1.JSON{Raw: []byte(`{"replicas":2}`)},
+			Helm: &oceanv1beta1.HelmOptions{
+				ValuesStrategy: oceanv1beta1.HelmValuesStrategyReuse,
+			},
+		},
+	}
+	desired := current.DeepCopy()
+	installed := &installer.Release{Version: "2.8.8", Revision: 1}
+
+	t.Run("whenRolledBackWithReusedValues", func(tt *testing.T) {
+		// the failed release coalesces the desired values over the reused ones
+		failed := &installer.Release{
+			Version:  "2.8.9",
+			Revision: 2,
+			Values:   map[string]interface{}{"replicas": 2, "args": []interface{}{"--kubelet-insecure-tls"}},
+		}
+		comp := current.DeepCopy()
+		comp.Status.LastRollback = newRollbackStatus(comp, desired, failed, installed, metav1.Now())
+
+		held, _, err := heldUpgrade(comp, desired, installed, time.Now())
+		assert.NoError(tt, err)
+		if assert.NotNil(tt, held) {
+			assert.Equal(tt, reasonRolledBack, held.Reason)
+		}
+	})
+
+	t.Run("whenChangedAfterRollback", func(tt *testing.T) {
+		failed := &installer.Release{Version: "2.8.9", Revision: 2}
+		comp := current.DeepCopy()
+		comp.Status.LastRollback = newRollbackStatus(comp, desired, failed, installed, metav1.Now())
+		comp.Generation++
+
+		held, _, err := heldUpgrade(comp, desired, installed, time.Now())
+		assert.NoError(tt, err)
+		assert.Nil(tt, held)
+	})
+}
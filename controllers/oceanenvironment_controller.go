@@ -0,0 +1,396 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	ctrlutil "github.com/spotinst/ocean-operator/internal/controller"
+	"github.com/spotinst/ocean-operator/pkg/log"
+	"github.com/spotinst/ocean-operator/pkg/tide"
+	"github.com/spotinst/ocean-operator/pkg/tide/values"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// These are valid reasons of the Ready condition of an OceanEnvironment.
+const (
+	environmentReasonComponentsReady    = "ComponentsReady"
+	environmentReasonComponentsNotReady = "ComponentsNotReady"
+	environmentReasonReconcileFailed    = "ReconcileFailed"
+	environmentReasonComponentConflict  = "ComponentConflict"
+)
+
+// OceanEnvironmentReconciler reconciles a OceanEnvironment object
+type OceanEnvironmentReconciler struct {
+	Scheme *runtime.Scheme
+	Client client.Client
+	Log    log.Logger
+}
+
+// +kubebuilder:rbac:groups=ocean.spot.io,resources=oceanenvironments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ocean.spot.io,resources=oceanenvironments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ocean.spot.io,resources=oceanenvironments/finalizers,verbs=update
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OceanEnvironmentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&oceanv1beta1.OceanEnvironment{}).
+		Owns(&oceanv1beta1.OceanComponent{}).
+		Complete(r)
+}
+
+type EnvironmentRequestContext struct {
+	ctrlutil.RequestContext
+	env *oceanv1beta1.OceanEnvironment
+	log log.Logger
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.9.5/pkg/reconcile
+func (r *OceanEnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	rctx := r.newContext(ctx, req)
+	rctx.log.Info("reconciling")
+
+	// get environment by name
+	rctx.env = new(oceanv1beta1.OceanEnvironment)
+	if err := r.Client.Get(ctx, req.NamespacedName, rctx.env); err != nil {
+		if !apierrors.IsNotFound(err) {
+			rctx.log.Error(err, "cannot retrieve")
+		}
+		return ctrlutil.NoRequeue()
+	}
+
+	// owned components are removed by the garbage collector
+	if ctrlutil.IsBeingDeleted(rctx.env) {
+		return ctrlutil.NoRequeue()
+	}
+
+	comps, err := r.desiredComponents(rctx)
+	if err != nil {
+		return r.reconcileFailed(rctx, err)
+	}
+	var conflicts []string
+	for _, comp := range comps {
+		owned, err := r.applyComponent(rctx, comp)
+		if err != nil {
+			return r.reconcileFailed(rctx, err)
+		}
+		if !owned && comp.Spec.State == oceanv1beta1.OceanComponentStatePresent {
+			conflicts = append(conflicts, comp.Name)
+		}
+	}
+	if err = r.deleteStaleComponents(rctx, comps); err != nil {
+		return r.reconcileFailed(rctx, err)
+	}
+
+	var ready *oceanv1beta1.OceanComponentCondition
+	if len(conflicts) > 0 {
+		ready = newConditionf(
+			oceanv1beta1.OceanComponentConditionTypeReady,
+			corev1.ConditionFalse,
+			environmentReasonComponentConflict,
+			"Components exist and are not owned by the environment: %s", strings.Join(conflicts, ", "),
+		)
+	}
+	if err = r.updateStatus(rctx, ready); err != nil {
+		return ctrlutil.RequeueError(err)
+	}
+	return ctrlutil.NoRequeue()
+}
+
+// desiredComponents returns the built-in components, placed in the namespace
+// of the environment and with its overrides applied. Components that are not
+// enabled by the environment are returned as absent.
+func (r *OceanEnvironmentReconciler) desiredComponents(
+	ctx *EnvironmentRequestContext) ([]*oceanv1beta1.OceanComponent, error) {
+	comps, err := tide.LoadComponents()
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := ctx.env.Spec.Namespace
+	if namespace == "" {
+		namespace = oceanv1beta1.NamespaceSystem
+	}
+
+	enabled := make(map[oceanv1beta1.OceanComponentName]oceanv1beta1.OceanEnvironmentComponent)
+	for _, c := range ctx.env.Spec.Components {
+		enabled[c.Name] = c
+	}
+
+	for _, comp := range comps {
+		comp.Namespace = namespace
+		override, present := enabled[comp.Spec.Name] // component name
+		if !present {
+			override, present = enabled[oceanv1beta1.OceanComponentName(comp.Name)] // resource name
+		}
+		if !present {
+			comp.Spec.State = oceanv1beta1.OceanComponentStateAbsent
+			continue
+		}
+		delete(enabled, override.Name)
+
+		comp.Spec.State = oceanv1beta1.OceanComponentStatePresent
+		if override.URL != "" {
+			comp.Spec.URL = override.URL
+		}
+		if override.Version != "" {
+			comp.Spec.Version = override.Version
+		}
+		if override.Values != nil {
+			v, err := values.Merge(values.FromJSON(comp.Spec.Values), values.FromJSON(override.Values))
+			if err != nil {
+				return nil, fmt.Errorf("invalid values of component %q: %w", override.Name, err)
+			}
+			if comp.Spec.Values, err = values.ToJSON(v); err != nil {
+				return nil, fmt.Errorf("invalid values of component %q: %w", override.Name, err)
+			}
+		}
+		if override.ValuesFrom != nil {
+			comp.Spec.ValuesFrom = override.ValuesFrom
+		}
+	}
+
+	// dependencies on components that are not enabled would never be available
+	present := make(map[string]struct{}, len(comps))
+	for _, comp := range comps {
+		if comp.Spec.State == oceanv1beta1.OceanComponentStatePresent {
+			present[comp.Name] = struct{}{}
+		}
+	}
+	for _, comp := range comps {
+		var deps []string
+		for _, dep := range comp.Spec.DependsOn {
+			if _, ok := present[dep]; ok {
+				deps = append(deps, dep)
+			}
+		}
+		comp.Spec.DependsOn = deps
+	}
+
+	if len(enabled) > 0 {
+		unknown := make([]string, 0, len(enabled))
+		for name := range enabled {
+			unknown = append(unknown, name.String())
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown components: %s", strings.Join(unknown, ", "))
+	}
+
+	return comps, nil
+}
+
+// applyComponent creates or patches the given component, owned by the
+// environment, and reports whether the environment owns it. Absent components
+// that do not exist are not created, and existing components the environment
+// does not own are left alone.
+//
+// The spec of an owned component is fully owned by the environment, and
+// changes made to it are overwritten, except for suspension.
+func (r *OceanEnvironmentReconciler) applyComponent(ctx *EnvironmentRequestContext,
+	desired *oceanv1beta1.OceanComponent) (bool, error) {
+	comp := &oceanv1beta1.OceanComponent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      desired.Name,
+			Namespace: desired.Namespace,
+		},
+	}
+	err := r.Client.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+	switch {
+	case apierrors.IsNotFound(err):
+		if desired.Spec.State == oceanv1beta1.OceanComponentStateAbsent {
+			return true, nil
+		}
+	case err != nil:
+		return false, err
+	case !ownsComponent(ctx.env, comp):
+		ctx.log.V(1).Info("component not owned by the environment", "name", comp.Name)
+		return false, nil
+	}
+	if desired.Spec.State == oceanv1beta1.OceanComponentStatePresent {
+		if err = ensureNamespace(ctx, r.Client, ctx.log, comp.Namespace); err != nil {
+			return false, err
+		}
+	}
+
+	op, err := controllerutil.CreateOrPatch(ctx, r.Client, comp, func() error {
+		// suspension is an operational toggle, set on the component by hand
+		suspend := comp.Spec.Suspend
+		comp.Spec = desired.Spec
+		comp.Spec.Suspend = suspend
+		if comp.Labels == nil {
+			comp.Labels = make(map[string]string, 1)
+		}
+		comp.Labels[oceanv1beta1.OceanEnvironmentLabel] = ctx.env.Name
+		return controllerutil.SetControllerReference(ctx.env, comp, r.Scheme)
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to apply component %q: %w", comp.Name, err)
+	}
+	if op != controllerutil.OperationResultNone {
+		ctx.log.Info("applied component", "name", comp.Name, "operation", op)
+	}
+	return true, nil
+}
+
+// deleteStaleComponents deletes the components controlled by the environment
+// that are not desired anymore, e.g., those left in the previous namespace of
+// the environment. Their releases are uninstalled before they are removed.
+func (r *OceanEnvironmentReconciler) deleteStaleComponents(ctx *EnvironmentRequestContext,
+	desired []*oceanv1beta1.OceanComponent) error {
+	list := new(oceanv1beta1.OceanComponentList)
+	if err := r.Client.List(ctx, list,
+		client.MatchingLabels{oceanv1beta1.OceanEnvironmentLabel: ctx.env.Name}); err != nil {
+		return err
+	}
+	keep := make(map[types.NamespacedName]struct{}, len(desired))
+	for _, comp := range desired {
+		keep[client.ObjectKeyFromObject(comp)] = struct{}{}
+	}
+	for i := range list.Items {
+		item := &list.Items[i]
+		if _, ok := keep[client.ObjectKeyFromObject(item)]; ok {
+			continue
+		}
+		if !metav1.IsControlledBy(item, ctx.env) || ctrlutil.IsBeingDeleted(item) {
+			continue
+		}
+		if err := r.Client.Delete(ctx, item); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("unable to delete component %s/%s: %w", item.Namespace, item.Name, err)
+		}
+		ctx.log.Info("deleted stale component", "namespace", item.Namespace, "name", item.Name)
+	}
+	return nil
+}
+
+// ownsComponent reports whether the environment owns the given component,
+// i.e., the component is controlled by the environment or, to adopt a
+// component created otherwise, carries the label of the environment and has
+// no controller.
+func ownsComponent(env *oceanv1beta1.OceanEnvironment, comp *oceanv1beta1.OceanComponent) bool {
+	if metav1.IsControlledBy(comp, env) {
+		return true
+	}
+	return comp.Labels[oceanv1beta1.OceanEnvironmentLabel] == env.Name &&
+		metav1.GetControllerOf(comp) == nil
+}
+
+func (r *OceanEnvironmentReconciler) reconcileFailed(ctx *EnvironmentRequestContext,
+	err error) (ctrl.Result, error) {
+	ctx.log.Error(err, "cannot reconcile")
+	if statusErr := r.updateStatus(ctx, newCondition(
+		oceanv1beta1.OceanComponentConditionTypeReady,
+		corev1.ConditionFalse,
+		environmentReasonReconcileFailed,
+		err.Error(),
+	)); statusErr != nil {
+		ctx.log.Error(statusErr, "cannot update status")
+	}
+	return ctrlutil.RequeueError(err)
+}
+
+// updateStatus aggregates the status of the owned components and patches the
+// environment's status if anything changed. Unless a Ready condition is given,
+// the environment is ready when all present components are ready.
+func (r *OceanEnvironmentReconciler) updateStatus(ctx *EnvironmentRequestContext,
+	ready *oceanv1beta1.OceanComponentCondition) error {
+	list := new(oceanv1beta1.OceanComponentList)
+	if err := r.Client.List(ctx, list,
+		client.MatchingLabels{oceanv1beta1.OceanEnvironmentLabel: ctx.env.Name}); err != nil {
+		return err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+
+	base := ctx.env.DeepCopy()
+	status := &ctx.env.Status
+	status.Components = nil
+
+	var notReady []string
+	for i := range list.Items {
+		item := &list.Items[i]
+		if !metav1.IsControlledBy(item, ctx.env) {
+			continue
+		}
+		cs := oceanv1beta1.OceanEnvironmentComponentStatus{
+			Name:  item.Name,
+			State: item.Spec.State,
+			Ready: corev1.ConditionUnknown,
+		}
+		if c := getCondition(item.Status, oceanv1beta1.OceanComponentConditionTypeReady); c != nil {
+			cs.Ready = c.Status
+			cs.Reason = c.Reason
+		}
+		if cs.State == oceanv1beta1.OceanComponentStatePresent && cs.Ready != corev1.ConditionTrue {
+			notReady = append(notReady, item.Name)
+		}
+		status.Components = append(status.Components, cs)
+	}
+
+	if ready == nil {
+		if len(notReady) == 0 {
+			ready = newCondition(
+				oceanv1beta1.OceanComponentConditionTypeReady,
+				corev1.ConditionTrue,
+				environmentReasonComponentsReady,
+				"All components are ready",
+			)
+		} else {
+			ready = newConditionf(
+				oceanv1beta1.OceanComponentConditionTypeReady,
+				corev1.ConditionFalse,
+				environmentReasonComponentsNotReady,
+				"Components are not ready: %s", strings.Join(notReady, ", "),
+			)
+		}
+	}
+	ready.ObservedGeneration = ctx.env.Generation
+
+	// conditions share their type and helpers with components
+	compStatus := &oceanv1beta1.OceanComponentStatus{Conditions: status.Conditions}
+	setCondition(compStatus, *ready)
+	status.Conditions = compStatus.Conditions
+	status.ObservedGeneration = ctx.env.Generation
+
+	if equality.Semantic.DeepEqual(base.Status, ctx.env.Status) {
+		return nil
+	}
+	if err := r.Client.Status().Patch(ctx, ctx.env, client.MergeFrom(base)); err != nil {
+		ctx.log.Error(err, "patch error")
+		return err
+	}
+	return nil
+}
+
+func (r *OceanEnvironmentReconciler) newContext(ctx context.Context, req ctrl.Request) *EnvironmentRequestContext {
+	// generate a new request id
+	reqID := ctrlutil.NewRequestId()
+
+	// initialize a new request logger
+	reqLog := ctrlutil.NewRequestLog(r.Log, req, reqID)
+
+	// initialize a new base context
+	reqCtx := ctrlutil.NewRequestContext(ctx, req, reqID, reqLog)
+
+	// initialize a new request context
+	return &EnvironmentRequestContext{
+		RequestContext: reqCtx,
+		log:            reqLog,
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/log"
+	"github.com/spotinst/ocean-operator/pkg/tide"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDesiredComponents(t *testing.T) {
+	newContext := func(spec oceanv1beta1.OceanEnvironmentSpec) *EnvironmentRequestContext {
+		return &EnvironmentRequestContext{
+			env: &oceanv1beta1.OceanEnvironment{Spec: spec},
+		}
+	}
+	byName := func(comps []*oceanv1beta1.OceanComponent) map[string]*oceanv1beta1.OceanComponent {
+		m := make(map[string]*oceanv1beta1.OceanComponent, len(comps))
+		for _, comp := range comps {
+			m[comp.Name] = comp
+		}
+		return m
+	}
+	r := new(OceanEnvironmentReconciler)
+
+	t.Run("whenNoComponents", func(tt *testing.T) {
+		comps, err := r.desiredComponents(newContext(oceanv1beta1.OceanEnvironmentSpec{}))
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, comps)
+		for _, comp := range comps {
+			assert.Equal(tt, oceanv1beta1.NamespaceSystem, comp.Namespace)
+			assert.Equal(tt, oceanv1beta1.OceanComponentStateAbsent, comp.Spec.State)
+		}
+	})
+
+	t.Run("whenOverridden", func(tt *testing.T) {
+		comps, err := r.desiredComponents(newContext(oceanv1beta1.OceanEnvironmentSpec{
+			Namespace: "foo",
+			Components: []oceanv1beta1.OceanEnvironmentComponent{
+				{
+					Name:    oceanv1beta1.MetricsServerComponentName,
+					Version: "1.2.3",
+				},
+				{
+					Name:   oceanv1beta1.OceanControllerComponentName,
+					Values: &apiextensionsv1.JSON{Raw: []byte(`{"secret":{"enabled":false}}`)},
+				},
+			},
+		}))
+		assert.NoError(tt, err)
+
+		m := byName(comps)
+		assert.Equal(tt, "foo", m["metrics-server"].Namespace)
+		assert.Equal(tt, oceanv1beta1.OceanComponentStatePresent, m["metrics-server"].Spec.State)
+		assert.Equal(tt, "1.2.3", m["metrics-server"].Spec.Version)
+		assert.Equal(tt, oceanv1beta1.OceanComponentStatePresent, m["ocean-controller"].Spec.State)
+		assert.Equal(tt, []string{"metrics-server"}, m["ocean-controller"].Spec.DependsOn)
+		assert.JSONEq(tt, `{"secret":{"enabled":false},"metrics-server":{"deployChart":false}}`,
+			string(m["ocean-controller"].Spec.Values.Raw))
+	})
+
+	t.Run("whenDependencyNotEnabled", func(tt *testing.T) {
+		comps, err := r.desiredComponents(newContext(oceanv1beta1.OceanEnvironmentSpec{
+			Components: []oceanv1beta1.OceanEnvironmentComponent{
+				{Name: oceanv1beta1.OceanControllerComponentName},
+			},
+		}))
+		assert.NoError(tt, err)
+		assert.Empty(tt, byName(comps)["ocean-controller"].Spec.DependsOn)
+	})
+
+	t.Run("whenUnknownComponent", func(tt *testing.T) {
+		_, err := r.desiredComponents(newContext(oceanv1beta1.OceanEnvironmentSpec{
+			Components: []oceanv1beta1.OceanEnvironmentComponent{
+				{Name: "foo"},
+			},
+		}))
+		assert.EqualError(tt, err, "unknown components: foo")
+	})
+}
+
+func TestApplyComponent(t *testing.T) {
+	env := &oceanv1beta1.OceanEnvironment{
+		ObjectMeta: metav1.ObjectMeta{Name: oceanv1beta1.DefaultOceanEnvironmentName, UID: "env"},
+	}
+	desired := &oceanv1beta1.OceanComponent{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-server", Namespace: oceanv1beta1.NamespaceSystem},
+		Spec: oceanv1beta1.OceanComponentSpec{
+			Name:    oceanv1beta1.MetricsServerComponentName,
+			State:   oceanv1beta1.OceanComponentStateAbsent,
+			Version: "2.8.8",
+		},
+	}
+	newReconciler := func(objs ...client.Object) (*OceanEnvironmentReconciler, *EnvironmentRequestContext) {
+		r := &OceanEnvironmentReconciler{
+			Scheme: tide.DefaultScheme(),
+			Client: fake.NewClientBuilder().WithScheme(tide.DefaultScheme()).WithObjects(objs...).Build(),
+			Log:    log.NullLogger,
+		}
+		ctx := r.newContext(context.Background(), ctrl.Request{})
+		ctx.env = env
+		return r, ctx
+	}
+	newExisting := func(labels map[string]string) *oceanv1beta1.OceanComponent {
+		return &oceanv1beta1.OceanComponent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      desired.Name,
+				Namespace: desired.Namespace,
+				Labels:    labels,
+			},
+			Spec: oceanv1beta1.OceanComponentSpec{
+				Name:    oceanv1beta1.MetricsServerComponentName,
+				State:   oceanv1beta1.OceanComponentStatePresent,
+				Version: "1.0.0",
+			},
+		}
+	}
+
+	t.Run("whenNotOwned", func(tt *testing.T) {
+		r, ctx := newReconciler(newExisting(nil))
+		owned, err := r.applyComponent(ctx, desired)
+		assert.NoError(tt, err)
+		assert.False(tt, owned)
+
+		comp := new(oceanv1beta1.OceanComponent)
+		assert.NoError(tt, r.Client.Get(ctx, client.ObjectKeyFromObject(desired), comp))
+		assert.Equal(tt, oceanv1beta1.OceanComponentStatePresent, comp.Spec.State)
+		assert.Equal(tt, "1.0.0", comp.Spec.Version)
+	})
+
+	t.Run("whenLabelled", func(tt *testing.T) {
+		r, ctx := newReconciler(newExisting(map[string]string{
+			oceanv1beta1.OceanEnvironmentLabel: env.Name,
+		}))
+		owned, err := r.applyComponent(ctx, desired)
+		assert.NoError(tt, err)
+		assert.True(tt, owned)
+
+		comp := new(oceanv1beta1.OceanComponent)
+		assert.NoError(tt, r.Client.Get(ctx, client.ObjectKeyFromObject(desired), comp))
+		assert.Equal(tt, oceanv1beta1.OceanComponentStateAbsent, comp.Spec.State)
+		assert.True(tt, metav1.IsControlledBy(comp, env))
+	})
+}
+
+func TestDeleteStaleComponents(t *testing.T) {
+	env := &oceanv1beta1.OceanEnvironment{
+		ObjectMeta: metav1.ObjectMeta{Name: oceanv1beta1.DefaultOceanEnvironmentName, UID: "env"},
+	}
+	newComponent := func(namespace string, controlled bool) *oceanv1beta1.OceanComponent {
+		comp := &oceanv1beta1.OceanComponent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "metrics-server",
+				Namespace: namespace,
+				Labels:    map[string]string{oceanv1beta1.OceanEnvironmentLabel: env.Name},
+			},
+		}
+		if controlled {
+			comp.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(env,
+				oceanv1beta1.GroupVersion.WithKind("OceanEnvironment"))}
+		}
+		return comp
+	}
+	r := &OceanEnvironmentReconciler{
+		Scheme: tide.DefaultScheme(),
+		Client: fake.NewClientBuilder().WithScheme(tide.DefaultScheme()).WithObjects(
+			newComponent("old", true),
+			newComponent("other", false),
+			newComponent("new", true),
+		).Build(),
+		Log: log.NullLogger,
+	}
+	ctx := r.newContext(context.Background(), ctrl.Request{})
+	ctx.env = env
+
+	assert.NoError(t, r.deleteStaleComponents(ctx, []*oceanv1beta1.OceanComponent{newComponent("new", false)}))
+
+	list := new(oceanv1beta1.OceanComponentList)
+	assert.NoError(t, r.Client.List(ctx, list))
+	var namespaces []string
+	for _, item := range list.Items {
+		namespaces = append(namespaces, item.Namespace)
+	}
+	assert.ElementsMatch(t, []string{"new", "other"}, namespaces)
+}
@@ -3,10 +3,13 @@ module github.com/spotinst/ocean-operator
 go 1.16
 
 require (
+	github.com/Masterminds/semver/v3 v3.1.1
+	github.com/evanphx/json-patch v4.11.0+incompatible
 	github.com/go-logr/logr v1.2.0
 	github.com/google/go-cmp v0.5.6
 	github.com/hashicorp/go-version v1.3.0
 	github.com/mitchellh/mapstructure v1.4.2
+	github.com/prometheus/client_golang v1.11.0
 	github.com/satori/go.uuid v1.2.0
 	github.com/spf13/cobra v1.2.1
 	github.com/spf13/pflag v1.0.5
@@ -21,4 +24,7 @@ require (
 	k8s.io/cli-runtime v0.22.4
 	k8s.io/client-go v0.22.4
 	sigs.k8s.io/controller-runtime v0.10.3
+	sigs.k8s.io/kustomize/api v0.8.11
+	sigs.k8s.io/kustomize/kyaml v0.11.0
+	sigs.k8s.io/yaml v1.2.0
 )
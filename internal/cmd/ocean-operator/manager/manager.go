@@ -5,17 +5,24 @@ package manager
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/spf13/cobra"
-	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
 	"github.com/spotinst/ocean-operator/controllers"
 	"github.com/spotinst/ocean-operator/internal/cli"
 	"github.com/spotinst/ocean-operator/internal/ocean"
 	"github.com/spotinst/ocean-operator/internal/version"
+	"github.com/spotinst/ocean-operator/pkg/installer/cache"
+	"github.com/spotinst/ocean-operator/pkg/installer/installers/plugin"
 	"github.com/spotinst/ocean-operator/pkg/tide"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	//+kubebuilder:scaffold:imports
@@ -28,8 +35,14 @@ type Options struct {
 	LeaderLock          string
 	MetricsAddress      string
 	ProbeAddress        string
+	EnableWebhooks      bool
 	BootstrapNamespace  string
 	BootstrapComponents *ocean.ComponentsFlag
+	ChartCacheDir       string
+	ChartCacheIndexTTL  time.Duration
+	ChartCacheMaxSize   int64
+	PluginDir           string
+	OperationTimeout    time.Duration
 
 	// internal
 	config  *rest.Config
@@ -61,13 +74,30 @@ func NewCommand(commonOptions *cli.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVar(&options.MetricsAddress, "metrics-bind-address", ":8080", "address the metric endpoint binds to")
 	cmd.Flags().StringVar(&options.ProbeAddress, "health-probe-bind-address", ":8081", "address the probe endpoint binds to")
 
+	// webhooks
+	cmd.Flags().BoolVar(&options.EnableWebhooks, "enable-webhooks", true, "enable the conversion and admission webhook server, which requires serving certificates in the webhook certificate directory and webhook configurations (see config/webhook); disable it to run without them, e.g. locally")
+
 	// leadership
 	cmd.Flags().BoolVar(&options.LeaderElection, "leader-elect", false, "enable leader election")
 	cmd.Flags().StringVar(&options.LeaderLock, "leader-lock", "6c511c84.spot.io", "leader election lock name")
 
-	// bootstrap
-	cmd.Flags().StringVar(&options.BootstrapNamespace, "bootstrap-namespace", oceanv1alpha1.NamespaceSystem, "namespace where components should be installed during environment bootstrapping")
-	cmd.Flags().Var(options.BootstrapComponents, "bootstrap-components", "list of components to install during environment bootstrapping")
+	// bootstrap: the flags only seed the default environment, which is managed
+	// through its OceanEnvironment once created. They stay because the
+	// published chart passes them (bootstrap.components), and the namespace is
+	// also the namespace the operator runs and watches references in.
+	cmd.Flags().StringVar(&options.BootstrapNamespace, "bootstrap-namespace", oceanv1beta1.NamespaceSystem, "namespace of the default environment created during bootstrapping, unless it already exists")
+	cmd.Flags().Var(options.BootstrapComponents, "bootstrap-components", "list of components enabled in the default environment created during bootstrapping, unless it already exists")
+
+	// chart cache
+	cmd.Flags().StringVar(&options.ChartCacheDir, "chart-cache-dir", filepath.Join(os.TempDir(), "ocean-operator", "cache"), "directory of the cache of downloaded repository indexes and charts, and of the credentials written while downloading them")
+	cmd.Flags().DurationVar(&options.ChartCacheIndexTTL, "chart-cache-index-ttl", 5*time.Minute, "time a cached repository index is used for before it is downloaded again")
+	cmd.Flags().Int64Var(&options.ChartCacheMaxSize, "chart-cache-max-size", 512<<20, "maximum size in bytes of the chart cache, beyond which the least recently used files are evicted (0 for no limit)")
+
+	// installer operations
+	cmd.Flags().DurationVar(&options.OperationTimeout, "operation-timeout", 15*time.Minute, "deadline of each install, upgrade, rollback and uninstall of a component, beyond which it fails (0 for no deadline)")
+
+	// plugins
+	cmd.Flags().StringVar(&options.PluginDir, "installer-plugin-dir", "/etc/ocean-operator/plugins", "directory of the descriptor files of out-of-process installer plugins")
 
 	return cmd
 }
@@ -77,7 +107,9 @@ func (x *Options) run(ctx context.Context) error {
 		x.printVersion,
 		x.setupConfig,
 		x.setupEnvironment,
+		x.setupPlugins,
 		x.setupManager,
+		x.setupWebhooks,
 		x.setupChecks,
 		x.startManager,
 	} {
@@ -124,6 +156,18 @@ func (x *Options) setupEnvironment(ctx context.Context) error {
 	return nil
 }
 
+func (x *Options) setupPlugins(ctx context.Context) error {
+	plugins, err := plugin.Discover(x.PluginDir)
+	if err != nil {
+		x.Log.Error(err, "unable to discover installer plugins")
+		return err
+	}
+	for _, p := range plugins {
+		x.Log.Info("discovered installer plugin", "name", p.Name)
+	}
+	return nil
+}
+
 func (x *Options) setupManager(ctx context.Context) (err error) {
 	x.manager, err = ctrl.NewManager(x.config, ctrl.Options{
 		Scheme:                 tide.DefaultScheme(),
@@ -132,27 +176,70 @@ func (x *Options) setupManager(ctx context.Context) (err error) {
 		HealthProbeBindAddress: x.ProbeAddress,
 		LeaderElection:         x.LeaderElection,
 		LeaderElectionID:       x.LeaderLock,
+		// ConfigMaps and Secrets are watched by their metadata only, and read
+		// from the API server, so that their data is never cached
+		ClientDisableCacheFor: []client.Object{
+			&corev1.ConfigMap{},
+			&corev1.Secret{},
+		},
 	})
 	if err != nil {
 		x.Log.Error(err, "unable to create runtime manager")
 		return err
 	}
 
+	chartCache, err := cache.New(cache.Options{
+		Dir:      x.ChartCacheDir,
+		IndexTTL: x.ChartCacheIndexTTL,
+		MaxSize:  x.ChartCacheMaxSize,
+	})
+	if err != nil {
+		x.Log.Error(err, "unable to create chart cache")
+		return err
+	}
+
 	if err = (&controllers.OceanComponentReconciler{
-		Scheme:       x.manager.GetScheme(),
-		Client:       x.manager.GetClient(),
-		ClientGetter: tide.NewConfigFlags(x.config, x.BootstrapNamespace),
-		Log:          x.Log.WithName("oceancomponent"),
-		Namespace:    x.BootstrapNamespace,
+		Scheme:           x.manager.GetScheme(),
+		Client:           x.manager.GetClient(),
+		ClientGetter:     tide.NewConfigFlags(x.config, x.BootstrapNamespace),
+		Log:              x.Log.WithName("oceancomponent"),
+		Recorder:         x.manager.GetEventRecorderFor("ocean-operator"),
+		Cache:            chartCache,
+		Namespace:        x.BootstrapNamespace,
+		OperationTimeout: x.OperationTimeout,
 	}).SetupWithManager(x.manager); err != nil {
 		x.Log.Error(err, "unable to create controller", "controller", "oceancomponent")
 		return err
 	}
 
+	if err = (&controllers.OceanEnvironmentReconciler{
+		Scheme: x.manager.GetScheme(),
+		Client: x.manager.GetClient(),
+		Log:    x.Log.WithName("oceanenvironment"),
+	}).SetupWithManager(x.manager); err != nil {
+		x.Log.Error(err, "unable to create controller", "controller", "oceanenvironment")
+		return err
+	}
+
 	//+kubebuilder:scaffold:builder
 	return nil
 }
 
+func (x *Options) setupWebhooks(ctx context.Context) error {
+	if !x.EnableWebhooks {
+		x.Log.Info("webhooks are disabled")
+		return nil
+	}
+
+	x.Log.Info("registering webhooks")
+	if err := (&oceanv1beta1.OceanComponent{}).SetupWebhookWithManager(x.manager); err != nil {
+		x.Log.Error(err, "unable to create webhook", "webhook", "oceancomponent")
+		return err
+	}
+
+	return nil
+}
+
 func (x *Options) setupChecks(ctx context.Context) error {
 	x.Log.Info("registering checks")
 	if err := x.manager.AddHealthzCheck("healthz", healthz.Ping); err != nil {
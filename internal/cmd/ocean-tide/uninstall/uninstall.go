@@ -7,7 +7,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
-	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
 	"github.com/spotinst/ocean-operator/internal/cli"
 	"github.com/spotinst/ocean-operator/pkg/tide"
 	"k8s.io/client-go/rest"
@@ -45,7 +45,7 @@ func NewCommand(commonOptions *cli.CommonOptions) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&options.ChartNamespace, "chart-namespace", oceanv1alpha1.NamespaceSystem, "chart namespace")
+	cmd.Flags().StringVar(&options.ChartNamespace, "chart-namespace", oceanv1beta1.NamespaceSystem, "chart namespace")
 	cmd.Flags().StringVar(&options.ChartName, "chart-name", tide.OceanOperatorChart, "chart name")
 	cmd.Flags().DurationVar(&options.Timeout, "timeout", 5*time.Minute, "maximum duration before timing out the execution")
 	cmd.Flags().BoolVar(&options.Wait, "wait", true, "wait for completion before exiting")
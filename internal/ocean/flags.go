@@ -4,21 +4,21 @@ import (
 	"errors"
 	"strings"
 
-	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
 	"github.com/spotinst/ocean-operator/pkg/log"
 )
 
 // ComponentsFlag implements pflag.Value interface to store component names,
 // allowing transforming and validating them while being set.
 type ComponentsFlag struct {
-	list map[oceanv1alpha1.OceanComponentName]struct{}
+	list map[oceanv1beta1.OceanComponentName]struct{}
 	log  log.Logger
 }
 
 // NewEmptyComponentsFlag returns a new ComponentsFlag with an empty list of components.
 func NewEmptyComponentsFlag(log log.Logger) *ComponentsFlag {
 	return &ComponentsFlag{
-		list: make(map[oceanv1alpha1.OceanComponentName]struct{}),
+		list: make(map[oceanv1beta1.OceanComponentName]struct{}),
 		log:  log,
 	}
 }
@@ -26,8 +26,9 @@ func NewEmptyComponentsFlag(log log.Logger) *ComponentsFlag {
 // NewDefaultComponentsFlag returns a new ComponentsFlag with a default list of components.
 func NewDefaultComponentsFlag(log log.Logger) *ComponentsFlag {
 	f := NewEmptyComponentsFlag(log)
-	f.list[oceanv1alpha1.OceanControllerComponentName] = struct{}{}
-	f.list[oceanv1alpha1.MetricsServerComponentName] = struct{}{}
+	for _, entry := range oceanv1beta1.Catalog() {
+		f.list[entry.Name] = struct{}{}
+	}
 	return f
 }
 
@@ -36,17 +37,14 @@ func (c *ComponentsFlag) Type() string {
 }
 
 func (c *ComponentsFlag) Set(arg string) error {
-	c.list = make(map[oceanv1alpha1.OceanComponentName]struct{})
+	c.list = make(map[oceanv1beta1.OceanComponentName]struct{})
 	v := strings.Split(arg, ",")
 	for _, val := range v {
-		name := oceanv1alpha1.OceanComponentName(val)
-		switch name {
-		case oceanv1alpha1.OceanControllerComponentName, oceanv1alpha1.MetricsServerComponentName:
+		name := oceanv1beta1.OceanComponentName(val)
+		if _, ok := oceanv1beta1.LookupCatalog(name); ok {
 			c.list[name] = struct{}{}
-		default:
-			if name != "" && c.log != nil {
-				c.log.Error(errors.New("unknown component name input, ignoring"), "name", name)
-			}
+		} else if name != "" && c.log != nil {
+			c.log.Error(errors.New("unknown component name input, ignoring"), "name", name)
 		}
 	}
 	return nil
@@ -64,8 +62,8 @@ func (c *ComponentsFlag) StringSlice() []string {
 	return strings.Split(c.String(), ",")
 }
 
-func (c *ComponentsFlag) List() []oceanv1alpha1.OceanComponentName {
-	s := make([]oceanv1alpha1.OceanComponentName, 0, len(c.list))
+func (c *ComponentsFlag) List() []oceanv1beta1.OceanComponentName {
+	s := make([]oceanv1beta1.OceanComponentName, 0, len(c.list))
 	for n := range c.list {
 		s = append(s, n)
 	}
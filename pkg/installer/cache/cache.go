@@ -0,0 +1,243 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is the kind of a cached file.
+type Kind string
+
+// These are valid kinds of cached files.
+const (
+	// KindIndex is the kind of repository indexes.
+	KindIndex Kind = "index"
+	// KindChart is the kind of chart archives.
+	KindChart Kind = "chart"
+)
+
+func (x Kind) String() string { return string(x) }
+
+// workDirName is the name of the working directory of a Cache.
+const workDirName = "work"
+
+// Options configures a Cache.
+type Options struct {
+	// Dir is the directory holding the cached files.
+	Dir string
+	// IndexTTL is the time a repository index is used for before it is
+	// downloaded again. Zero means indexes never expire.
+	IndexTTL time.Duration
+	// MaxSize is the maximum total size, in bytes, of the cached files,
+	// beyond which the least recently used are evicted. Zero means no limit.
+	MaxSize int64
+}
+
+// FetchFunc writes the content of a cached file to the given path.
+type FetchFunc func(path string) error
+
+// Cache is an on-disk cache of repository indexes and chart archives, shared
+// by concurrent installers.
+type Cache struct {
+	options Options
+
+	mu      sync.Mutex // guards entries
+	entries map[string]*entry
+}
+
+// entry is a cached file.
+type entry struct {
+	mu       sync.Mutex // held while the file is fetched or used
+	refs     int
+	lastUsed time.Time
+}
+
+// New returns a Cache with the given options, creating its directory.
+func New(options Options) (*Cache, error) {
+	if options.Dir == "" {
+		return nil, fmt.Errorf("cache: directory is required")
+	}
+	for _, name := range []string{KindIndex.String(), KindChart.String(), workDirName} {
+		if err := os.MkdirAll(filepath.Join(options.Dir, name), 0700); err != nil {
+			return nil, fmt.Errorf("cache: unable to create directory: %w", err)
+		}
+	}
+	return &Cache{
+		options: options,
+		entries: make(map[string]*entry),
+	}, nil
+}
+
+// Dir returns the directory holding the cached files.
+func (c *Cache) Dir() string { return c.options.Dir }
+
+// WorkDir returns the working directory of the cache, which holds the files
+// used while fetching, such as credentials. They are not cached, and must be
+// removed by their users.
+func (c *Cache) WorkDir() string { return filepath.Join(c.options.Dir, workDirName) }
+
+// IndexTTL returns the time a repository index is used for.
+func (c *Cache) IndexTTL() time.Duration { return c.options.IndexTTL }
+
+// Get calls fn with the path of the cached file of the given kind and key,
+// fetching it first when missing or older than the given TTL (zero meaning
+// no expiry). The file exists at least until fn returns.
+func (c *Cache) Get(kind Kind, key string, ttl time.Duration, fetch FetchFunc, fn func(path string) error) error {
+	path := c.path(kind, key)
+	e := c.acquire(path)
+	defer c.release(e)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	fi, err := os.Stat(path)
+	switch {
+	case err == nil && (ttl == 0 || time.Since(fi.ModTime()) < ttl):
+		requestsTotal.WithLabelValues(kind.String(), resultHit).Inc()
+	case err == nil || os.IsNotExist(err):
+		requestsTotal.WithLabelValues(kind.String(), resultMiss).Inc()
+		if err = c.fetch(path, fetch); err != nil {
+			return err
+		}
+		c.evict()
+	default:
+		return err
+	}
+
+	return fn(path)
+}
+
+// Invalidate removes the cached file of the given kind and key, so that it is
+// fetched again on next use.
+func (c *Cache) Invalidate(kind Kind, key string) error {
+	path := c.path(kind, key)
+	e := c.acquire(path)
+	defer c.release(e)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// fetch fetches the given file, replacing it atomically.
+func (c *Cache) fetch(path string, fetch FetchFunc) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".fetch-")
+	if err != nil {
+		return fmt.Errorf("cache: unable to create file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	defer os.Remove(tmpPath)
+
+	if err = fetch(tmpPath); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// evict removes the least recently used files not in use until the total size
+// of the cached files is within the maximum size.
+func (c *Cache) evict() {
+	if c.options.MaxSize <= 0 {
+		return
+	}
+
+	type file struct {
+		path     string
+		size     int64
+		lastUsed time.Time
+	}
+	var (
+		files []file
+		total int64
+	)
+	for _, kind := range []Kind{KindIndex, KindChart} {
+		infos, err := ioutil.ReadDir(filepath.Join(c.options.Dir, kind.String()))
+		if err != nil {
+			continue
+		}
+		for _, fi := range infos {
+			if fi.IsDir() || fi.Name()[0] == '.' {
+				continue
+			}
+			files = append(files, file{
+				path:     filepath.Join(c.options.Dir, kind.String(), fi.Name()),
+				size:     fi.Size(),
+				lastUsed: fi.ModTime(),
+			})
+			total += fi.Size()
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range files {
+		if e, ok := c.entries[files[i].path]; ok && e.lastUsed.After(files[i].lastUsed) {
+			files[i].lastUsed = e.lastUsed
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].lastUsed.Before(files[j].lastUsed) })
+
+	for _, f := range files {
+		if total <= c.options.MaxSize {
+			break
+		}
+		if e, ok := c.entries[f.path]; ok && e.refs > 0 {
+			continue // in use
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+			delete(c.entries, f.path)
+			evictionsTotal.Inc()
+		}
+	}
+	sizeBytes.Set(float64(total))
+}
+
+// acquire returns the entry of the given file, marking it in use.
+func (c *Cache) acquire(path string) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[path]
+	if !ok {
+		e = new(entry)
+		c.entries[path] = e
+	}
+	e.refs++
+	e.lastUsed = time.Now()
+	return e
+}
+
+// release marks the given entry no longer in use.
+func (c *Cache) release(e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.refs--
+}
+
+// path returns the path of the cached file of the given kind and key.
+func (c *Cache) path(kind Kind, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.options.Dir, kind.String(), hex.EncodeToString(sum[:]))
+}
+
+// Key returns a cache key made of the given parts.
+func Key(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	fetches := 0
+	fetch := func(data string) FetchFunc {
+		return func(path string) error {
+			fetches++
+			return ioutil.WriteFile(path, []byte(data), 0600)
+		}
+	}
+	read := func(out *string) func(string) error {
+		return func(path string) error {
+			data, err := ioutil.ReadFile(path)
+			*out = string(data)
+			return err
+		}
+	}
+
+	t.Run("whenHit", func(tt *testing.T) {
+		c, err := New(Options{Dir: tt.TempDir()})
+		assert.NoError(tt, err)
+		fetches = 0
+
+		var out string
+		assert.NoError(tt, c.Get(KindChart, Key("foo", "1.0.0"), 0, fetch("foo"), read(&out)))
+		assert.NoError(tt, c.Get(KindChart, Key("foo", "1.0.0"), 0, fetch("bar"), read(&out)))
+		assert.Equal(tt, "foo", out)
+		assert.Equal(tt, 1, fetches)
+	})
+
+	t.Run("whenExpired", func(tt *testing.T) {
+		c, err := New(Options{Dir: tt.TempDir()})
+		assert.NoError(tt, err)
+		fetches = 0
+
+		var out string
+		assert.NoError(tt, c.Get(KindIndex, Key("foo"), time.Minute, fetch("foo"), read(&out)))
+		old := time.Now().Add(-time.Hour)
+		assert.NoError(tt, os.Chtimes(c.path(KindIndex, Key("foo")), old, old))
+		assert.NoError(tt, c.Get(KindIndex, Key("foo"), time.Minute, fetch("bar"), read(&out)))
+		assert.Equal(tt, "bar", out)
+		assert.Equal(tt, 2, fetches)
+	})
+
+	t.Run("whenOverMaxSize", func(tt *testing.T) {
+		c, err := New(Options{Dir: tt.TempDir(), MaxSize: 5})
+		assert.NoError(tt, err)
+
+		var out string
+		assert.NoError(tt, c.Get(KindChart, Key("foo"), 0, fetch("foo"), read(&out)))
+		assert.NoError(tt, c.Get(KindChart, Key("bar"), 0, fetch("bar"), read(&out)))
+		_, err = os.Stat(c.path(KindChart, Key("foo")))
+		assert.True(tt, os.IsNotExist(err))
+		_, err = os.Stat(c.path(KindChart, Key("bar")))
+		assert.NoError(tt, err)
+	})
+}
@@ -0,0 +1,36 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package cache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// These are results of cache lookups.
+const (
+	resultHit  = "hit"
+	resultMiss = "miss"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ocean_operator_chart_cache_requests_total",
+		Help: "Total number of chart cache lookups, by kind and result (hit or miss).",
+	}, []string{"kind", "result"})
+
+	evictionsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ocean_operator_chart_cache_evictions_total",
+		Help: "Total number of files evicted from the chart cache.",
+	})
+
+	sizeBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ocean_operator_chart_cache_size_bytes",
+		Help: "Total size of the files in the chart cache, as of the last eviction.",
+	})
+)
+
+func init() {
+	// exposed by the metrics endpoint of the manager
+	metrics.Registry.MustRegister(requestsTotal, evictionsTotal, sizeBytes)
+}
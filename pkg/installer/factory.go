@@ -50,8 +50,8 @@ func GetFactory(name string) (Factory, error) {
 		return factory, nil
 	}
 
-	return nil, fmt.Errorf("installer: no factory function found for "+
-		"installer %q (missing import?)", name)
+	return nil, fmt.Errorf("%w: no factory function found for "+
+		"installer %q (missing import?)", ErrInstallerNotFound, name)
 }
 
 // GetInstance returns an instance of installer by name.
@@ -0,0 +1,275 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/spotinst/ocean-operator/pkg/installer/cache"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// withCache calls fn with the cache of the installer or, when it has none,
+// with a temporary cache removed afterwards.
+func (i *Installer) withCache(fn func(c *cache.Cache) error) error {
+	if i.Cache != nil {
+		return fn(i.Cache)
+	}
+
+	dir, err := ioutil.TempDir(os.TempDir(), "oceancache-")
+	if err != nil {
+		return fmt.Errorf("unable to create cache directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			i.Log.Error(err, "could not delete cache directory", "path", dir)
+		}
+	}()
+
+	c, err := cache.New(cache.Options{Dir: dir})
+	if err != nil {
+		return err
+	}
+	return fn(c)
+}
+
+// newSettings returns the Helm settings of calls using the given cache, whose
+// downloads and credentials are written to its working directory.
+func newSettings(c *cache.Cache) *cli.EnvSettings {
+	settings := new(cli.EnvSettings)
+	settings.RepositoryCache = c.WorkDir()
+	return settings
+}
+
+// writeTempFile writes the given data to a new file of the given directory,
+// named after the given pattern as ioutil.TempFile does, and returns its path.
+func writeTempFile(dir, pattern string, data []byte) (string, error) {
+	f, err := ioutil.TempFile(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Close()
+	} else {
+		_ = f.Close()
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// timeoutGetter is a getter.Getter whose downloads time out.
+type timeoutGetter struct {
+	getter.Getter
+	timeout time.Duration
+}
+
+// newTimeoutGetter returns the given getter, whose downloads time out when the
+// given context expires.
+func newTimeoutGetter(ctx context.Context, g getter.Getter) getter.Getter {
+	return &timeoutGetter{Getter: g, timeout: timeoutFor(ctx, 0)}
+}
+
+func (g *timeoutGetter) Get(url string, options ...getter.Option) (*bytes.Buffer, error) {
+	if g.timeout > 0 {
+		options = append(options, getter.WithTimeout(g.timeout))
+	}
+	return g.Getter.Get(url, options...)
+}
+
+// getIndex returns the index of the repository at the given URL, which is
+// downloaded again once older than the index TTL of the given cache.
+func (i *Installer) getIndex(ctx context.Context, c *cache.Cache, repoURL string, creds *repositoryCredentials,
+	settings *cli.EnvSettings) (*repo.IndexFile, error) {
+	key := indexKey(repoURL, creds)
+	var index *repo.IndexFile
+	fetch := func(path string) error {
+		// the downloaded files are named after the repository, so that
+		// concurrent downloads of other indexes do not overwrite them
+		entry := &repo.Entry{Name: sha256Digest([]byte(key)), URL: repoURL}
+		creds.applyToEntry(entry)
+
+		chartRepo, err := repo.NewChartRepository(entry, getter.All(settings))
+		if err != nil {
+			return fmt.Errorf("invalid repository %s: %w", repoURL, err)
+		}
+		chartRepo.CachePath = settings.RepositoryCache
+		chartRepo.Client = newTimeoutGetter(ctx, chartRepo.Client)
+
+		indexPath, err := chartRepo.DownloadIndexFile()
+		if err != nil {
+			return fmt.Errorf("failed to download index of repository %s: %w", repoURL, err)
+		}
+		defer os.Remove(indexPath)
+		defer os.Remove(filepath.Join(chartRepo.CachePath, helmpath.CacheChartsFile(entry.Name)))
+		data, err := ioutil.ReadFile(indexPath)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path, data, 0600)
+	}
+	load := func(path string) (err error) {
+		if index, err = repo.LoadIndexFile(path); err != nil {
+			return fmt.Errorf("failed to load index of repository %s: %w", repoURL, err)
+		}
+		return nil
+	}
+
+	if err := c.Get(cache.KindIndex, key, c.IndexTTL(), fetch, load); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// indexKey returns the cache key of the index of the repository at the given
+// URL. Indexes fetched with credentials are cached separately per credentials,
+// as they may list charts that other credentials cannot access.
+func indexKey(repoURL string, creds *repositoryCredentials) string {
+	if creds.digest == "" {
+		return cache.Key(repoURL)
+	}
+	return cache.Key(repoURL, creds.digest)
+}
+
+// findChartVersion returns the latest version of the given chart, in the
+// repository at the given URL, satisfying the given version constraint. The
+// index is downloaded again if a cached index has no such version, since it
+// may predate it.
+func (i *Installer) findChartVersion(ctx context.Context, c *cache.Cache, repoURL, chartName, version string,
+	creds *repositoryCredentials, settings *cli.EnvSettings) (*repo.ChartVersion, error) {
+	for attempt := 0; ; attempt++ {
+		index, err := i.getIndex(ctx, c, repoURL, creds, settings)
+		if err != nil {
+			return nil, err
+		}
+		cv, err := index.Get(chartName, version)
+		if err == nil {
+			return cv, nil
+		}
+		if attempt > 0 {
+			return nil, fmt.Errorf("chart %s version %q not found in repository %s: %w",
+				chartName, version, repoURL, err)
+		}
+		if err = c.Invalidate(cache.KindIndex, indexKey(repoURL, creds)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// getChart returns the given chart version of the repository at the given
+// URL, which is only downloaded when missing from the given cache. Charts are
+// verified by the given verifier, and cached separately per credentials and
+// keyring.
+func (i *Installer) getChart(ctx context.Context, c *cache.Cache, repoURL string, cv *repo.ChartVersion,
+	creds *repositoryCredentials, verifier *chartVerifier, settings *cli.EnvSettings) (*chart.Chart, error) {
+	if len(cv.URLs) == 0 {
+		return nil, fmt.Errorf("chart %s version %s has no downloadable URLs", cv.Name, cv.Version)
+	}
+	chartURL, err := repo.ResolveReferenceURL(repoURL, cv.URLs[0])
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(chartURL)
+	if err != nil {
+		return nil, err
+	}
+	g, err := getter.All(settings).ByScheme(u.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	g = newTimeoutGetter(ctx, g)
+	download := func(href string) ([]byte, error) {
+		// credentials are only passed to other hosts when so configured
+		data, err := g.Get(href,
+			getter.WithURL(repoURL),
+			getter.WithBasicAuth(creds.Username, creds.Password),
+			getter.WithPassCredentialsAll(creds.PassCredentialsAll),
+			getter.WithTLSClientConfig(creds.CertFile, creds.KeyFile, creds.CAFile),
+			getter.WithInsecureSkipVerifyTLS(creds.InsecureSkipTLSVerify),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", href, err)
+		}
+		return data.Bytes(), nil
+	}
+
+	var chrt *chart.Chart
+	fetch := func(cachePath string) error {
+		data, err := download(chartURL)
+		if err != nil {
+			return err
+		}
+		if err = verifyDigest(data, cv.Digest); err != nil {
+			return fmt.Errorf("failed to verify chart %s: %w", chartURL, err)
+		}
+		if verifier.verifiesProvenance() {
+			prov, err := download(chartURL + ".prov")
+			if err != nil {
+				return fmt.Errorf("%w: %v", installer.ErrVerificationFailed, err)
+			}
+			// the provenance file lists the archive by its file name
+			dir, err := ioutil.TempDir(settings.RepositoryCache, "provenance-")
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := os.RemoveAll(dir); err != nil {
+					i.Log.Error(err, "could not delete directory", "path", dir)
+				}
+			}()
+			archivePath := filepath.Join(dir, path.Base(u.Path))
+			if err = ioutil.WriteFile(archivePath, data, 0600); err != nil {
+				return err
+			}
+			if err = ioutil.WriteFile(archivePath+".prov", prov, 0600); err != nil {
+				return err
+			}
+			if err = verifier.verifyProvenance(archivePath, archivePath+".prov"); err != nil {
+				return err
+			}
+		}
+		return ioutil.WriteFile(cachePath, data, 0600)
+	}
+	load := func(cachePath string) error {
+		data, err := ioutil.ReadFile(cachePath)
+		if err != nil {
+			return err
+		}
+		if err = verifier.verifyDigest(data); err != nil {
+			return fmt.Errorf("failed to verify chart %s: %w", chartURL, err)
+		}
+		if chrt, err = loader.LoadArchive(bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("failed to load chart %s: %w", chartURL, err)
+		}
+		return nil
+	}
+
+	// charts fetched with credentials are never served to other credentials
+	key := cache.Key(repoURL, cv.Name, cv.Version, cv.Digest)
+	if creds.digest != "" {
+		key = cache.Key(key, creds.digest)
+	}
+	if verifier.verifiesProvenance() {
+		key = cache.Key(key, verifier.keyringDigest)
+	}
+	if err = c.Get(cache.KindChart, key, 0, fetch, load); err != nil {
+		return nil, err
+	}
+	return chrt, nil
+}
@@ -3,29 +3,41 @@
 package helm
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"os"
 	"strings"
+	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/google/go-cmp/cmp"
-	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
 	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/spotinst/ocean-operator/pkg/installer/cache"
 	"github.com/spotinst/ocean-operator/pkg/log"
-	"gopkg.in/yaml.v3"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
-	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/chartutil"
 	_ "helm.sh/helm/v3/pkg/downloader"
-	_ "helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
 	"helm.sh/helm/v3/pkg/storage/driver"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
 )
 
+// defaultTimeout is the default time to wait for each Kubernetes operation.
+const defaultTimeout = 5 * time.Minute
+
 func init() {
-	installer.MustRegister(oceanv1alpha1.OceanComponentTypeHelm.String(),
+	installer.MustRegister(oceanv1beta1.OceanComponentTypeHelm.String(),
 		func(options *installer.InstallerOptions) (installer.Installer, error) {
 			return NewInstaller(options), nil
 		})
@@ -35,6 +47,7 @@ type Installer struct {
 	ClientGetter genericclioptions.RESTClientGetter
 	Namespace    string
 	DryRun       bool
+	Cache        *cache.Cache
 	Log          log.Logger
 }
 
@@ -44,17 +57,21 @@ func NewInstaller(options *installer.InstallerOptions) *Installer {
 		ClientGetter: options.ClientGetter,
 		Namespace:    options.Namespace,
 		DryRun:       options.DryRun,
+		Cache:        options.Cache,
 		Log:          options.Log,
 	}
 }
 
-func (i *Installer) Get(name oceanv1alpha1.OceanComponentName) (*installer.Release, error) {
+func (i *Installer) Get(ctx context.Context, name string) (*installer.Release, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	config, err := i.getActionConfig(i.Namespace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get action configuration: %w", err)
 	}
 
-	rel, err := action.NewGet(config).Run(name.String())
+	rel, err := action.NewGet(config).Run(name)
 	if err != nil {
 		if errors.Is(err, driver.ErrReleaseNotFound) {
 			return nil, installer.ErrReleaseNotFound
@@ -70,10 +87,10 @@ func (i *Installer) Get(name oceanv1alpha1.OceanComponentName) (*installer.Relea
 	return i.translateRelease(rel, values), nil
 }
 
-func (i *Installer) Install(component *oceanv1alpha1.OceanComponent) (*installer.Release, error) {
-	values := make(map[string]interface{})
-	if err := yaml.Unmarshal([]byte(component.Spec.Values), &values); err != nil {
-		return nil, fmt.Errorf("invalid values configuration: %w", err)
+func (i *Installer) Install(ctx context.Context, component *oceanv1beta1.OceanComponent) (*installer.Release, error) {
+	values, err := decodeValues(component.Spec.Values)
+	if err != nil {
+		return nil, err
 	}
 	i.Log.V(5).Info("install values configuration", "values", values)
 
@@ -82,50 +99,46 @@ func (i *Installer) Install(component *oceanv1alpha1.OceanComponent) (*installer
 		return nil, fmt.Errorf("failed to get action configuration: %w", err)
 	}
 
-	chartName := component.Spec.Name.String()
-	rel, err := action.NewGet(config).Run(chartName)
+	releaseName := component.ReleaseName()
+	rel, err := action.NewGet(config).Run(releaseName)
 	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
 		return nil, fmt.Errorf("existing release check failed: %w", err)
 	} else if rel != nil {
-		i.Log.Info("release already exists", "name", chartName)
+		i.Log.Info("release already exists", "name", releaseName)
 		return i.translateRelease(rel, values), nil
 	}
 
+	opts := getHelmOptions(component)
+	config.Releases.MaxHistory = opts.MaxHistory
+
 	act := action.NewInstall(config)
-	act.ReleaseName = chartName
+	act.ReleaseName = releaseName
 	act.Namespace = i.Namespace
 	act.DryRun = i.DryRun
 	act.ChartPathOptions.RepoURL = component.Spec.URL
 	act.ChartPathOptions.Version = component.Spec.Version
 	act.CreateNamespace = true
-
-	settings := new(cli.EnvSettings)
-	cache, err := ioutil.TempDir(os.TempDir(), "oceancache-")
-	if err != nil {
-		return nil, fmt.Errorf("unable to create cache directory: %w", err)
-	}
-	defer func() {
-		err := os.RemoveAll(cache)
-		if err != nil {
-			i.Log.Error(err, "could not delete cache directory", "path", cache)
-		}
-	}()
-	settings.RepositoryCache = cache
-	settings.Debug = i.DryRun // renders out invalid yaml
-
-	// Check for the existence of a file called 'chartName' in the current directory.
-	// If it exists, it will assume that is the chart and it won't download the chart.
-	cp, err := act.ChartPathOptions.LocateChart(chartName, settings)
+	act.Wait = opts.Wait
+	act.WaitForJobs = opts.WaitForJobs
+	act.Atomic = opts.Atomic
+	act.DisableHooks = opts.DisableHooks
+	act.SkipCRDs = opts.CRDs != oceanv1beta1.HelmCRDsPolicyCreate // upgraded below
+	act.PostRenderer = newPostRenderer(component, act.Namespace)
+
+	chart, err := i.loadChart(ctx, component, &act.ChartPathOptions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to locate chart %s: %w", chartName, err)
+		return nil, err
 	}
 
-	chart, err := loader.Load(cp)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load chart %s: %w", cp, err)
+	if opts.CRDs == oceanv1beta1.HelmCRDsPolicyUpgrade {
+		if err = i.applyCRDs(ctx, config, chart, true); err != nil {
+			return nil, err
+		}
 	}
 
-	rel, err = act.Run(chart, values)
+	// the chart has been loaded within the deadline of the context
+	act.Timeout = timeoutFor(ctx, opts.Timeout.Duration)
+	rel, err = act.RunWithContext(ctx, chart, values)
 	if err != nil {
 		return nil, fmt.Errorf("installation error: %w", err)
 	}
@@ -134,30 +147,38 @@ func (i *Installer) Install(component *oceanv1alpha1.OceanComponent) (*installer
 	return i.translateRelease(rel, values), nil
 }
 
-func (i *Installer) Uninstall(component *oceanv1alpha1.OceanComponent) error {
+func (i *Installer) Uninstall(ctx context.Context, component *oceanv1beta1.OceanComponent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	config, err := i.getActionConfig(i.Namespace)
 	if err != nil {
 		return fmt.Errorf("failed to get action configuration: %w", err)
 	}
 
+	opts := getHelmOptions(component)
+
 	act := action.NewUninstall(config)
 	act.DryRun = i.DryRun
+	act.Wait = opts.Wait
+	act.Timeout = timeoutFor(ctx, opts.Timeout.Duration)
+	act.DisableHooks = opts.DisableHooks
 
-	chartName := component.Spec.Name.String()
-	_, err = act.Run(chartName)
+	releaseName := component.ReleaseName()
+	_, err = act.Run(releaseName)
 	if err != nil {
 		i.Log.Error(err, fmt.Sprintf("ignoring deletion error: %v", err))
 	} else {
-		i.Log.Info("uninstalled", "name", chartName)
+		i.Log.Info("uninstalled", "name", releaseName)
 	}
 
 	return nil
 }
 
-func (i *Installer) Upgrade(component *oceanv1alpha1.OceanComponent) (*installer.Release, error) {
-	values := make(map[string]interface{})
-	if err := yaml.Unmarshal([]byte(component.Spec.Values), &values); err != nil {
-		return nil, fmt.Errorf("invalid values configuration: %w", err)
+func (i *Installer) Upgrade(ctx context.Context, component *oceanv1beta1.OceanComponent) (*installer.Release, error) {
+	values, err := decodeValues(component.Spec.Values)
+	if err != nil {
+		return nil, err
 	}
 	i.Log.V(5).Info("upgrade values configuration", "values", values)
 
@@ -166,66 +187,126 @@ func (i *Installer) Upgrade(component *oceanv1alpha1.OceanComponent) (*installer
 		return nil, fmt.Errorf("failed to get action configuration: %w", err)
 	}
 
+	opts := getHelmOptions(component)
+
 	act := action.NewUpgrade(config)
 	act.Namespace = i.Namespace
 	act.DryRun = i.DryRun
 	act.ChartPathOptions.RepoURL = component.Spec.URL
 	act.ChartPathOptions.Version = component.Spec.Version
-	act.ReuseValues = true
-
-	settings := new(cli.EnvSettings)
-	cacheDir, err := ioutil.TempDir(os.TempDir(), "oceancache-")
+	act.Wait = opts.Wait
+	act.WaitForJobs = opts.WaitForJobs
+	act.Atomic = opts.Atomic
+	act.MaxHistory = opts.MaxHistory
+	act.DisableHooks = opts.DisableHooks
+	act.ReuseValues = opts.ValuesStrategy == oceanv1beta1.HelmValuesStrategyReuse
+	act.ResetValues = opts.ValuesStrategy == oceanv1beta1.HelmValuesStrategyReset
+	act.PostRenderer = newPostRenderer(component, act.Namespace)
+
+	chart, err := i.loadChart(ctx, component, &act.ChartPathOptions)
 	if err != nil {
-		return nil, fmt.Errorf("unable to create cache directory: %w", err)
+		return nil, err
 	}
-	defer func() {
-		err := os.RemoveAll(cacheDir)
-		if err != nil {
-			i.Log.Error(err, "could not delete cache directory", "path", cacheDir)
+
+	// Helm itself leaves CRDs alone on upgrade
+	if opts.CRDs != oceanv1beta1.HelmCRDsPolicySkip {
+		if err = i.applyCRDs(ctx, config, chart, opts.CRDs == oceanv1beta1.HelmCRDsPolicyUpgrade); err != nil {
+			return nil, err
 		}
-	}()
-	settings.RepositoryCache = cacheDir
-	settings.Debug = i.DryRun // renders out invalid yaml
+	}
 
-	chartName := component.Spec.Name.String()
-	cp, err := act.ChartPathOptions.LocateChart(chartName, settings)
+	// the chart has been loaded within the deadline of the context
+	act.Timeout = timeoutFor(ctx, opts.Timeout.Duration)
+	rel, err := act.RunWithContext(ctx, component.ReleaseName(), chart, values)
 	if err != nil {
-		return nil, fmt.Errorf("failed to locate chart %s: %w", chartName, err)
+		return nil, fmt.Errorf("installation error: %w", err)
 	}
 
-	chart, err := loader.Load(cp)
+	i.Log.Info("upgraded", "name", rel.Name)
+	return i.translateRelease(rel, values), nil
+}
+
+func (i *Installer) Rollback(ctx context.Context, component *oceanv1beta1.OceanComponent, revision int) (*installer.Release, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	config, err := i.getActionConfig(i.Namespace)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load chart %s: %w", cp, err)
+		return nil, fmt.Errorf("failed to get action configuration: %w", err)
+	}
+
+	opts := getHelmOptions(component)
+
+	act := action.NewRollback(config)
+	act.Version = revision
+	act.DryRun = i.DryRun
+	act.Wait = opts.Wait
+	act.WaitForJobs = opts.WaitForJobs
+	act.Timeout = timeoutFor(ctx, opts.Timeout.Duration)
+	act.MaxHistory = opts.MaxHistory
+	act.DisableHooks = opts.DisableHooks
+
+	releaseName := component.ReleaseName()
+	if err = act.Run(releaseName); err != nil {
+		return nil, fmt.Errorf("rollback error: %w", err)
 	}
 
-	rel, err := act.Run(chartName, chart, values)
+	rel, err := action.NewGet(config).Run(releaseName)
 	if err != nil {
-		return nil, fmt.Errorf("installation error: %w", err)
+		return nil, fmt.Errorf("failed to get release %s: %w", releaseName, err)
 	}
 
-	i.Log.Info("upgraded", "name", rel.Name)
-	return i.translateRelease(rel, values), nil
+	i.Log.Info("rolled back", "name", rel.Name, "revision", revision)
+	return i.translateRelease(rel, rel.Config), nil
+}
+
+func (i *Installer) History(ctx context.Context, name string) ([]*installer.Release, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	config, err := i.getActionConfig(i.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get action configuration: %w", err)
+	}
+
+	rels, err := action.NewHistory(config).Run(name)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil, installer.ErrReleaseNotFound
+		}
+		return nil, err
+	}
+	releaseutil.SortByRevision(rels)
+
+	history := make([]*installer.Release, 0, len(rels))
+	for _, rel := range rels {
+		history = append(history, i.translateRelease(rel, rel.Config))
+	}
+	return history, nil
 }
 
-func (i *Installer) IsUpgrade(component *oceanv1alpha1.OceanComponent, release *installer.Release) bool {
+func (i *Installer) IsUpgrade(ctx context.Context, component *oceanv1beta1.OceanComponent, release *installer.Release) bool {
 	if component.Spec.Version != release.Version {
 		return true
 	}
 
-	newValues := make(map[string]interface{})
-	if err := yaml.Unmarshal([]byte(component.Spec.Values), &newValues); err != nil {
+	newValues, err := decodeValues(component.Spec.Values)
+	if err != nil {
 		i.Log.Error(err, "failed to unmarshal values")
 		return true // fail properly later
 	}
-	if newValues == nil {
-		newValues = make(map[string]interface{})
-	}
 
 	oldValues := make(map[string]interface{})
 	if release.Values != nil {
 		oldValues = release.Values
 	}
 
+	// reused values are merged under the new ones on upgrade, as Helm does, so
+	// keys missing from the new values are not a change
+	if getHelmOptions(component).ValuesStrategy == oceanv1beta1.HelmValuesStrategyReuse {
+		newValues = chartutil.CoalesceTables(newValues, oldValues)
+	}
+
 	if diff := strings.TrimSpace(cmp.Diff(newValues, oldValues)); diff != "" {
 		i.Log.V(5).Info("upgrade is required", "diff", diff)
 		return true
@@ -234,36 +315,272 @@ func (i *Installer) IsUpgrade(component *oceanv1alpha1.OceanComponent, release *
 	return false
 }
 
+func (i *Installer) ResolveVersion(ctx context.Context, component *oceanv1beta1.OceanComponent) (string, error) {
+	if component.Spec.Source != nil {
+		return i.resolveSourceVersion(ctx, component)
+	}
+	if isExactVersion(component.Spec.Version) {
+		return component.Spec.Version, nil
+	}
+
+	// credentials are written to the working directory of the cache, and
+	// removed once the version is resolved
+	var version string
+	err := i.withCache(func(c *cache.Cache) error {
+		settings := newSettings(c)
+		creds, err := i.getRepositoryCredentials(ctx, component, c.WorkDir())
+		if err != nil {
+			return err
+		}
+		defer creds.removeFiles()
+
+		chartName := component.ChartName()
+		if isOCI(component.Spec.URL) {
+			ref := ociChartRef(component.Spec.URL, chartName)
+			if version, err = resolveOCIVersion(ctx, ref, component.Spec.Version, creds); err != nil {
+				return fmt.Errorf("failed to resolve version %q of chart %s: %w",
+					component.Spec.Version, chartName, err)
+			}
+			return nil
+		}
+
+		cv, err := i.findChartVersion(ctx, c, component.Spec.URL, chartName, component.Spec.Version, creds, settings)
+		if err != nil {
+			return fmt.Errorf("failed to resolve version %q of chart %s: %w",
+				component.Spec.Version, chartName, err)
+		}
+		version = cv.Version
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	i.Log.V(5).Info("resolved version", "name", component.ChartName(),
+		"constraint", component.Spec.Version, "version", version)
+	return version, nil
+}
+
+// loadChart loads the chart of the given component, from its source when
+// set, or from the repository or registry of the given chart options
+// otherwise. Downloads time out when the given context expires.
+func (i *Installer) loadChart(ctx context.Context, component *oceanv1beta1.OceanComponent,
+	opts *action.ChartPathOptions) (*chart.Chart, error) {
+	var chrt *chart.Chart
+	err := i.withCache(func(c *cache.Cache) (err error) {
+		chrt, err = i.loadChartWithCache(ctx, c, component, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chrt, nil
+}
+
+// loadChartWithCache loads the chart of the given component as loadChart
+// does, sharing the indexes and charts of repositories of the given cache.
+// Credentials and keyrings are written to its working directory, and removed
+// once the chart is loaded.
+func (i *Installer) loadChartWithCache(ctx context.Context, c *cache.Cache, component *oceanv1beta1.OceanComponent,
+	opts *action.ChartPathOptions) (*chart.Chart, error) {
+	settings := newSettings(c)
+	settings.Debug = i.DryRun // renders out invalid yaml
+
+	// charts failing verification are never installed
+	verifier, err := i.getChartVerifier(ctx, component, c.WorkDir())
+	if err != nil {
+		return nil, err
+	}
+	defer verifier.removeFiles()
+	if component.Spec.Source != nil {
+		return i.loadSourceChart(ctx, component, verifier)
+	}
+
+	creds, err := i.getRepositoryCredentials(ctx, component, c.WorkDir())
+	if err != nil {
+		return nil, err
+	}
+	defer creds.removeFiles()
+	creds.applyToChartPathOptions(opts)
+
+	chartName := component.ChartName()
+	if isOCI(opts.RepoURL) {
+		// charts in OCI registries are pulled using Helm's registry client
+		data, err := i.pullOCIChart(ctx, opts, chartName, creds, verifier, settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate chart %s: %w", chartName, err)
+		}
+		if err = verifier.verifyDigest(data); err != nil {
+			return nil, fmt.Errorf("failed to verify chart %s: %w", chartName, err)
+		}
+		chrt, err := loader.LoadArchive(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load chart %s: %w", chartName, err)
+		}
+		return chrt, nil
+	}
+
+	// indexes and charts of repositories are shared between installers
+	cv, err := i.findChartVersion(ctx, c, opts.RepoURL, chartName, opts.Version, creds, settings)
+	if err != nil {
+		return nil, err
+	}
+	return i.getChart(ctx, c, opts.RepoURL, cv, creds, verifier, settings)
+}
+
+// applyCRDs creates the missing CRDs of the given chart and, if upgrade is set,
+// upgrades the existing ones. It waits for them to be established.
+func (i *Installer) applyCRDs(ctx context.Context, config *action.Configuration, chrt *chart.Chart, upgrade bool) error {
+	if i.DryRun {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var applied kube.ResourceList
+	for _, crd := range chrt.CRDObjects() {
+		res, err := config.KubeClient.Build(bytes.NewBuffer(crd.File.Data), false)
+		if err != nil {
+			return fmt.Errorf("failed to build CRD %s: %w", crd.Name, err)
+		}
+		if upgrade {
+			_, err = config.KubeClient.Update(res, res, false)
+		} else if _, err = config.KubeClient.Create(res); apierrors.IsAlreadyExists(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply CRD %s: %w", crd.Name, err)
+		}
+		applied = append(applied, res...)
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+
+	// invalidate the discovery cache, since it will not have the new CRDs
+	discoveryClient, err := config.RESTClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return err
+	}
+	discoveryClient.Invalidate()
+	if err = config.KubeClient.Wait(applied, timeoutFor(ctx, time.Minute)); err != nil {
+		return fmt.Errorf("failed to wait for CRDs: %w", err)
+	}
+	_, _ = discoveryClient.ServerGroups()
+
+	i.Log.Info("applied CRDs", "count", len(applied), "upgrade", upgrade)
+	return nil
+}
+
+// getHelmOptions returns the Helm options of the given component, with their
+// defaults set.
+func getHelmOptions(component *oceanv1beta1.OceanComponent) oceanv1beta1.HelmOptions {
+	var opts oceanv1beta1.HelmOptions
+	if component.Spec.Helm != nil {
+		opts = *component.Spec.Helm.DeepCopy()
+	}
+	if opts.Timeout == nil {
+		opts.Timeout = &metav1.Duration{Duration: defaultTimeout}
+	}
+	if opts.ValuesStrategy == "" {
+		opts.ValuesStrategy = oceanv1beta1.HelmValuesStrategyReset
+	}
+	if opts.CRDs == "" {
+		opts.CRDs = oceanv1beta1.HelmCRDsPolicyCreate
+	}
+	return opts
+}
+
+// timeoutFor returns the given timeout, shortened to the time left before the
+// deadline of the given context, if any. Helm actions that take no context
+// give up once it expires.
+func timeoutFor(ctx context.Context, timeout time.Duration) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return timeout
+	}
+	left := time.Until(deadline)
+	if left <= 0 {
+		return time.Nanosecond // zero means no timeout for some actions
+	}
+	if timeout > 0 && timeout < left {
+		return timeout
+	}
+	return left
+}
+
 // https://stackoverflow.com/questions/59782217/run-helm3-client-from-in-cluster
 func (i *Installer) getActionConfig(namespace string) (*action.Configuration, error) {
 	config := new(action.Configuration)
 	if err := config.Init(i.ClientGetter, namespace, "secret", i.actionLogger); err != nil {
 		return nil, err
 	}
+	// objects without a namespace belong to the release namespace, rather
+	// than to the namespace of the client getter
+	if kc, ok := config.KubeClient.(*kube.Client); ok {
+		kc.Namespace = namespace
+	}
 	return config, nil
 }
 
+// getKubeClient returns a Kubernetes client of the cluster.
+func (i *Installer) getKubeClient() (kubernetes.Interface, error) {
+	config, err := i.ClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
+
 // actionLogger returns an action.DebugLog that uses Zap to log.
 func (i *Installer) actionLogger(format string, v ...interface{}) {
 	i.Log.Info(fmt.Sprintf(format, v...))
 }
 
+// decodeValues decodes the given JSON values into a map. A nil or empty value
+// results in an empty map.
+func decodeValues(values *apiextensionsv1.JSON) (map[string]interface{}, error) {
+	out := make(map[string]interface{})
+	if values == nil || len(values.Raw) == 0 {
+		return out, nil
+	}
+	if err := json.Unmarshal(values.Raw, &out); err != nil {
+		return nil, fmt.Errorf("invalid values configuration: %w", err)
+	}
+	if out == nil { // literal null
+		out = make(map[string]interface{})
+	}
+	return out, nil
+}
+
+// isExactVersion reports whether the given version is an exact version, as
+// opposed to an empty version or a version constraint.
+func isExactVersion(version string) bool {
+	_, err := semver.StrictNewVersion(strings.TrimPrefix(version, "v"))
+	return err == nil
+}
+
 func (i *Installer) translateRelease(rel *release.Release, values map[string]interface{}) *installer.Release {
 	return &installer.Release{
-		Name:        rel.Name,
-		Version:     rel.Chart.Metadata.Version,
-		AppVersion:  rel.Chart.Metadata.AppVersion,
-		Description: rel.Info.Description,
-		Manifest:    rel.Manifest,
-		Status:      i.translateReleaseStatus(rel.Info.Status),
-		Values:      values,
+		Name:         rel.Name,
+		Version:      rel.Chart.Metadata.Version,
+		AppVersion:   rel.Chart.Metadata.AppVersion,
+		Revision:     rel.Version,
+		Description:  rel.Info.Description,
+		Manifest:     rel.Manifest,
+		Status:       i.translateReleaseStatus(rel.Info.Status),
+		Values:       values,
+		LastDeployed: rel.Info.LastDeployed.Time,
 	}
 }
 
 func (i *Installer) translateReleaseStatus(status release.Status) installer.ReleaseStatus {
 	switch status {
-	case release.StatusFailed, release.StatusSuperseded:
+	case release.StatusFailed:
 		return installer.ReleaseStatusFailed
+	case release.StatusSuperseded:
+		return installer.ReleaseStatusSuperseded
 	case release.StatusPendingInstall, release.StatusPendingRollback, release.StatusPendingUpgrade, release.StatusUninstalling:
 		return installer.ReleaseStatusProgressing
 	case release.StatusUninstalled:
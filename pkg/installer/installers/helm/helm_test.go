@@ -3,13 +3,29 @@
 package helm
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
-	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
 	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/spotinst/ocean-operator/pkg/installer/cache"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
@@ -32,15 +48,14 @@ serviceAccount:
 	assert.Equal(t, true, s["create"])
 }
 
-func getVersionedObjects(componentVersion, releasedVersion string) (*oceanv1alpha1.OceanComponent, *installer.Release) {
-	return &oceanv1alpha1.OceanComponent{
+func getVersionedObjects(componentVersion, releasedVersion string) (*oceanv1beta1.OceanComponent, *installer.Release) {
+	return &oceanv1beta1.OceanComponent{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: "ocean-foo",
 			},
-			Spec: oceanv1alpha1.OceanComponentSpec{
+			Spec: oceanv1beta1.OceanComponentSpec{
 				Name:    "foo",
 				Version: componentVersion,
-				Values:  "",
 			},
 		},
 		&installer.Release{
@@ -49,15 +64,19 @@ func getVersionedObjects(componentVersion, releasedVersion string) (*oceanv1alph
 		}
 }
 
-func getValuesObjects(componentValues string, releasedValues map[string]interface{}) (*oceanv1alpha1.OceanComponent, *installer.Release) {
-	return &oceanv1alpha1.OceanComponent{
+func getValuesObjects(componentValues string, releasedValues map[string]interface{}) (*oceanv1beta1.OceanComponent, *installer.Release) {
+	var values *apiextensionsv1.JSON
+	if componentValues != "" {
+		values = &apiextensionsv1.JSON{Raw: []byte(componentValues)}
+	}
+	return &oceanv1beta1.OceanComponent{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: "ocean-foo",
 			},
-			Spec: oceanv1alpha1.OceanComponentSpec{
+			Spec: oceanv1beta1.OceanComponentSpec{
 				Name:    "foo",
 				Version: "v1.2",
-				Values:  componentValues,
+				Values:  values,
 			},
 		},
 		&installer.Release{
@@ -74,33 +93,382 @@ func TestIsUpgrade(t *testing.T) {
 		ClientGetter: nil,
 		Log:          logger,
 	} // fix getClient for more complex tests
+	isUpgrade := func(comp *oceanv1beta1.OceanComponent, rel *installer.Release) bool {
+		return i.IsUpgrade(context.TODO(), comp, rel)
+	}
 	var u bool
 
-	u = i.IsUpgrade(getVersionedObjects("v1.1.0", "v0.9.8"))
+	u = isUpgrade(getVersionedObjects("v1.1.0", "v0.9.8"))
 	assert.True(t, u)
 
-	u = i.IsUpgrade(getVersionedObjects("v1.1.0", "v1.1.0"))
+	u = isUpgrade(getVersionedObjects("v1.1.0", "v1.1.0"))
 	assert.False(t, u)
 
-	u = i.IsUpgrade(getValuesObjects("metricsEnabled: true", map[string]interface{}{}))
+	u = isUpgrade(getValuesObjects(`{"metricsEnabled": true}`, map[string]interface{}{}))
 	assert.True(t, u)
 
-	u = i.IsUpgrade(getValuesObjects("", map[string]interface{}{}))
+	u = isUpgrade(getValuesObjects("", map[string]interface{}{}))
 	assert.False(t, u)
 
-	u = i.IsUpgrade(getValuesObjects(":unparseable yaml is an upgrade lol:", map[string]interface{}{}))
+	u = isUpgrade(getValuesObjects(":unparseable yaml is an upgrade lol:", map[string]interface{}{}))
 	assert.True(t, u)
 
-	v1 := `
-serviceAccount:
-  create: true
-`
+	v1 := `{"serviceAccount": {"create": true}}`
 	v2 := map[string]interface{}{
 		"serviceAccount": map[string]interface{}{
 			"create": true,
 		},
 	}
-	u = i.IsUpgrade(getValuesObjects(v1, v2))
+	u = isUpgrade(getValuesObjects(v1, v2))
 	assert.False(t, u)
 
+	// removed keys are an upgrade, unless values are reused
+	comp, rel := getValuesObjects(`{}`, v2)
+	assert.True(t, isUpgrade(comp, rel))
+	comp.Spec.Helm = &oceanv1beta1.HelmOptions{ValuesStrategy: oceanv1beta1.HelmValuesStrategyReuse}
+	assert.False(t, isUpgrade(comp, rel))
+	comp.Spec.Values = &apiextensionsv1.JSON{Raw: []byte(`{"serviceAccount": {"name": "foo"}}`)}
+	assert.True(t, isUpgrade(comp, rel))
+}
+
+func TestResolveVersion(t *testing.T) {
+	index := `apiVersion: v1
+entries:
+  foo:
+  - name: foo
+    version: 1.0.90
+  - name: foo
+    version: 1.0.95
+  - name: foo
+    version: 1.1.0
+  - name: foo
+    version: 2.0.0-rc.1
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(index))
+	}))
+	defer server.Close()
+
+	i := &Installer{Log: zap.New(zap.UseDevMode(true)).WithValues("test", t.Name())}
+	tests := []struct {
+		name     string
+		version  string
+		resolved string
+	}{
+		{name: "whenExact", version: "1.0.0", resolved: "1.0.0"},
+		{name: "whenEmpty", version: "", resolved: "1.1.0"},
+		{name: "whenTilde", version: "~1.0", resolved: "1.0.95"},
+		{name: "whenRange", version: ">=1.0.90 <2", resolved: "1.1.0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			comp, _ := getVersionedObjects(test.version, "")
+			comp.Spec.URL = server.URL
+			v, err := i.ResolveVersion(context.TODO(), comp)
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.resolved, v)
+		})
+	}
+
+	t.Run("whenCached", func(tt *testing.T) {
+		c, err := cache.New(cache.Options{Dir: tt.TempDir()})
+		assert.NoError(tt, err)
+		ci := &Installer{Log: i.Log, Cache: c}
+
+		comp, _ := getVersionedObjects("~1.0", "")
+		comp.Spec.URL = server.URL
+		v, err := ci.ResolveVersion(context.TODO(), comp)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "1.0.95", v)
+
+		// only the index is kept, in the cache
+		files, err := ioutil.ReadDir(c.WorkDir())
+		assert.NoError(tt, err)
+		assert.Empty(tt, files)
+	})
+
+	t.Run("whenUnsatisfiable", func(tt *testing.T) {
+		comp, _ := getVersionedObjects("^3", "")
+		comp.Spec.URL = server.URL
+		_, err := i.ResolveVersion(context.TODO(), comp)
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenDeadlineExceeded", func(tt *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		comp, _ := getVersionedObjects("~1.0", "")
+		comp.Spec.URL = server.URL
+		_, err := i.ResolveVersion(ctx, comp)
+		assert.Error(tt, err)
+	})
+}
+
+func TestTimeoutFor(t *testing.T) {
+	assert.Equal(t, time.Minute, timeoutFor(context.Background(), time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	assert.Equal(t, time.Minute, timeoutFor(ctx, time.Minute))
+	assert.InDelta(t, float64(time.Hour), float64(timeoutFor(ctx, 2*time.Hour)), float64(time.Minute))
+	assert.InDelta(t, float64(time.Hour), float64(timeoutFor(ctx, 0)), float64(time.Minute))
+}
+
+func TestGetHelmOptions(t *testing.T) {
+	comp, _ := getVersionedObjects("1.0.0", "")
+
+	opts := getHelmOptions(comp)
+	assert.Equal(t, defaultTimeout, opts.Timeout.Duration)
+	assert.Equal(t, oceanv1beta1.HelmValuesStrategyReset, opts.ValuesStrategy)
+	assert.Equal(t, oceanv1beta1.HelmCRDsPolicyCreate, opts.CRDs)
+
+	comp.Spec.Helm = &oceanv1beta1.HelmOptions{
+		Timeout:        &metav1.Duration{Duration: time.Minute},
+		ValuesStrategy: oceanv1beta1.HelmValuesStrategyReuse,
+		CRDs:           oceanv1beta1.HelmCRDsPolicySkip,
+	}
+	opts = getHelmOptions(comp)
+	assert.Equal(t, time.Minute, opts.Timeout.Duration)
+	assert.Equal(t, oceanv1beta1.HelmValuesStrategyReuse, opts.ValuesStrategy)
+	assert.Equal(t, oceanv1beta1.HelmCRDsPolicySkip, opts.CRDs)
+}
+
+func TestNewRepositoryCredentials(t *testing.T) {
+	opts := &oceanv1beta1.RepositoryOptions{
+		SecretRef:          &corev1.LocalObjectReference{Name: "charts"},
+		PassCredentialsAll: true,
+	}
+
+	t.Run("whenNoSecret", func(tt *testing.T) {
+		creds, err := newRepositoryCredentials(opts, nil, tt.TempDir())
+		assert.NoError(tt, err)
+		assert.Equal(tt, &repositoryCredentials{PassCredentialsAll: true}, creds)
+	})
+
+	t.Run("whenSecret", func(tt *testing.T) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "charts"},
+			Data: map[string][]byte{
+				corev1.BasicAuthUsernameKey:    []byte("foo"),
+				corev1.BasicAuthPasswordKey:    []byte("bar"),
+				corev1.ServiceAccountRootCAKey: []byte("ca"),
+			},
+		}
+		dir := tt.TempDir()
+		creds, err := newRepositoryCredentials(opts, secret, dir)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "foo", creds.Username)
+		assert.Equal(tt, "bar", creds.Password)
+		assert.Empty(tt, creds.CertFile)
+		assert.Equal(tt, dir, filepath.Dir(creds.CAFile))
+		data, err := ioutil.ReadFile(creds.CAFile)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "ca", string(data))
+
+		creds.removeFiles()
+		_, err = os.Stat(creds.CAFile)
+		assert.True(tt, os.IsNotExist(err))
+
+		// other credentials never share cached indexes or charts
+		other := secret.DeepCopy()
+		other.Data[corev1.BasicAuthPasswordKey] = []byte("baz")
+		otherCreds, err := newRepositoryCredentials(opts, other, tt.TempDir())
+		assert.NoError(tt, err)
+		assert.NotEqual(tt, indexKey("https://charts", creds), indexKey("https://charts", otherCreds))
+		assert.NotEqual(tt, indexKey("https://charts", creds), indexKey("https://charts", new(repositoryCredentials)))
+	})
+
+	t.Run("whenCertWithoutKey", func(tt *testing.T) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "charts"},
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+		}
+		_, err := newRepositoryCredentials(opts, secret, tt.TempDir())
+		assert.Error(tt, err)
+	})
+}
+
+func TestResolveOCIVersion(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if username, password, ok := r.BasicAuth(); !ok || username != "foo" || password != "bar" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token":"baz"}`))
+		case "/v2/charts/foo/tags/list":
+			if r.Header.Get("Authorization") != "Bearer baz" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+					`Bearer realm="%s/token",service="registry",scope="repository:charts/foo:pull"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"name":"charts/foo","tags":["1.0.90","1.0.95","1.1.0","2.0.0-rc.1","latest"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	creds := &repositoryCredentials{
+		DockerConfig:          []byte(fmt.Sprintf(`{"auths":{"%s":{"auth":"Zm9vOmJhcg=="}}}`, host)),
+		InsecureSkipTLSVerify: true,
+	}
+	ref := ociChartRef("oci://"+host+"/charts", "foo")
+
+	t.Run("whenConstraint", func(tt *testing.T) {
+		v, err := resolveOCIVersion(context.TODO(), ref, "~1.0", creds)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "1.0.95", v)
+	})
+
+	t.Run("whenEmpty", func(tt *testing.T) {
+		v, err := resolveOCIVersion(context.TODO(), ref, "", creds)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "1.1.0", v)
+	})
+
+	t.Run("whenUnauthorized", func(tt *testing.T) {
+		_, err := resolveOCIVersion(context.TODO(), ref, "", &repositoryCredentials{InsecureSkipTLSVerify: true})
+		assert.Error(tt, err)
+	})
+}
+
+func TestResolveSourceVersion(t *testing.T) {
+	dir := t.TempDir()
+	chart := "apiVersion: v2\nname: foo\nversion: 1.2.3\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte(chart), 0644))
+
+	i := &Installer{Log: zap.New(zap.UseDevMode(true)).WithValues("test", t.Name())}
+	tests := []struct {
+		name    string
+		path    string
+		version string
+		err     bool
+	}{
+		{name: "whenEmpty", path: dir, version: ""},
+		{name: "whenFileURL", path: "file://" + dir, version: "~1.2"},
+		{name: "whenUnsatisfiable", path: dir, version: "^2", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			comp, _ := getVersionedObjects(test.version, "")
+			comp.Spec.Source = &oceanv1beta1.ChartSource{Path: test.path}
+			v, err := i.ResolveVersion(context.TODO(), comp)
+			if test.err {
+				assert.Error(tt, err)
+				return
+			}
+			assert.NoError(tt, err)
+			assert.Equal(tt, "1.2.3", v)
+		})
+	}
+}
+
+func TestLoadSourceChartDigest(t *testing.T) {
+	dir := t.TempDir()
+	chart := "apiVersion: v2\nname: foo\nversion: 1.2.3\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte(chart), 0644))
+	chrt, err := loader.Load(dir)
+	assert.NoError(t, err)
+	archive, err := chartutil.Save(chrt, t.TempDir())
+	assert.NoError(t, err)
+	data, err := ioutil.ReadFile(archive)
+	assert.NoError(t, err)
+
+	i := &Installer{Log: zap.New(zap.UseDevMode(true)).WithValues("test", t.Name())}
+	tests := []struct {
+		name   string
+		path   string
+		digest string
+		err    bool
+	}{
+		{name: "whenMatching", path: archive, digest: sha256Digest(data)},
+		{name: "whenMismatching", path: archive, digest: sha256Digest([]byte("bar")), err: true},
+		{name: "whenDirectory", path: dir, digest: sha256Digest(data), err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			comp, _ := getVersionedObjects("", "")
+			comp.Spec.Source = &oceanv1beta1.ChartSource{Path: test.path}
+			comp.Spec.Digest = "sha256:" + test.digest
+			verifier := &chartVerifier{digest: test.digest}
+			_, err := i.loadSourceChart(context.TODO(), comp, verifier)
+			if test.err {
+				assert.True(tt, installer.IsVerificationFailed(err))
+				return
+			}
+			assert.NoError(tt, err)
+		})
+	}
+}
+
+func TestPostRenderer(t *testing.T) {
+	manifest := `---
+# Source: foo/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  labels:
+    app: foo
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:1.0
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: foo
+  namespace: bar
+spec:
+  size: 1
+`
+	comp, _ := getVersionedObjects("", "")
+	comp.Spec.PostRender = &oceanv1beta1.PostRender{
+		CommonLabels: map[string]string{"team": "ocean"},
+		PatchesStrategicMerge: []apiextensionsv1.JSON{
+			{Raw: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"foo"},` +
+				`"spec":{"template":{"spec":{"containers":[{"name":"sidecar","image":"sidecar:1.0"}]}}}}`)},
+			{Raw: []byte(`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"foo"},"spec":{"size":2}}`)},
+		},
+		PatchesJSON6902: []oceanv1beta1.JSON6902Patch{{
+			Target: oceanv1beta1.PatchTarget{Group: "apps", Kind: "Deployment", Name: "foo", Namespace: "spot-system"},
+			Patch:  "- op: add\n  path: /spec/template/spec/tolerations\n  value: [{operator: Exists}]\n",
+		}},
+	}
+
+	t.Run("whenPatchesMatch", func(tt *testing.T) {
+		out, err := newPostRenderer(comp, "spot-system").Run(bytes.NewBufferString(manifest))
+		assert.NoError(tt, err)
+		objs, err := decodeManifest(out)
+		assert.NoError(tt, err)
+		assert.Len(tt, objs, 2)
+
+		deploy := objs[0]
+		assert.Equal(tt, map[string]string{"app": "foo", "team": "ocean"}, deploy.GetLabels())
+		containers, _, _ := unstructured.NestedSlice(deploy.Object, "spec", "template", "spec", "containers")
+		assert.Len(tt, containers, 2) // merged by name
+		tolerations, _, _ := unstructured.NestedSlice(deploy.Object, "spec", "template", "spec", "tolerations")
+		assert.Len(tt, tolerations, 1)
+
+		size, _, _ := unstructured.NestedFieldNoCopy(objs[1].Object, "spec", "size")
+		assert.EqualValues(tt, 2, size)
+	})
+
+	t.Run("whenPatchMatchesNothing", func(tt *testing.T) {
+		_, err := newPostRenderer(comp, "default").Run(bytes.NewBufferString(manifest))
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenNotConfigured", func(tt *testing.T) {
+		comp, _ := getVersionedObjects("", "")
+		assert.Nil(tt, newPostRenderer(comp, "spot-system"))
+	})
 }
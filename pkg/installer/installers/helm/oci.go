@@ -0,0 +1,362 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package helm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/helmpath"
+)
+
+const (
+	// ociScheme is the URL scheme of OCI registries.
+	ociScheme = "oci"
+	// ociCredentialsFile is the name of the file Helm's registry client
+	// reads registry credentials from, in the Helm configuration directory.
+	ociCredentialsFile = "registry.json"
+)
+
+// ociCredentialsMu serializes pulls from OCI registries, since Helm's registry
+// client reads its credentials from the process-wide configuration directory.
+var ociCredentialsMu sync.Mutex
+
+// isOCI reports whether the given URL refers to an OCI registry.
+func isOCI(url string) bool {
+	return strings.HasPrefix(url, ociScheme+"://")
+}
+
+// ociChartRef returns the reference of the given chart in the OCI registry at
+// the given URL.
+func ociChartRef(registryURL, chartName string) string {
+	return strings.TrimSuffix(registryURL, "/") + "/" + chartName
+}
+
+// pullOCIChart pulls the given chart from the OCI registry at the URL of the
+// given chart options, verifies its provenance if so configured, and returns
+// its archive. The files pulled and the registry credentials are removed.
+func (i *Installer) pullOCIChart(ctx context.Context, opts *action.ChartPathOptions, chartName string,
+	creds *repositoryCredentials, verifier *chartVerifier, settings *cli.EnvSettings) ([]byte, error) {
+	// the chart is referenced by its full location, rather than looked up
+	// in the index of a repository
+	ociOpts := *opts
+	ociOpts.RepoURL = ""
+	ociOpts.Version = strings.ReplaceAll(opts.Version, "+", "_") // as pushed
+
+	ociCredentialsMu.Lock()
+	defer ociCredentialsMu.Unlock()
+
+	// Helm pulls from registries without a context, so give up at least
+	// when it expires while waiting for other pulls
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(creds.DockerConfig) > 0 {
+		dir := filepath.Join(settings.RepositoryCache, "registry")
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("unable to create registry config directory: %w", err)
+		}
+		credsPath := filepath.Join(dir, ociCredentialsFile)
+		if err := ioutil.WriteFile(credsPath, creds.DockerConfig, 0600); err != nil {
+			return nil, fmt.Errorf("unable to write registry credentials: %w", err)
+		}
+		defer os.Remove(credsPath)
+		restore := setEnv(helmpath.ConfigHomeEnvVar, dir)
+		defer restore()
+	}
+
+	ref := ociChartRef(opts.RepoURL, chartName)
+	cp, err := ociOpts.LocateChart(ref, settings)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(cp)
+	if !verifier.verifiesProvenance() {
+		return ioutil.ReadFile(cp)
+	}
+
+	// the provenance file is pulled separately, since Helm does not report
+	// why verification fails
+	g, err := getter.All(settings).ByScheme(ociScheme)
+	if err != nil {
+		return nil, err
+	}
+	prov, err := g.Get(ref+".prov", getter.WithTagName(ociOpts.Version))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to pull provenance of %s: %v", installer.ErrVerificationFailed, ref, err)
+	}
+	if err = ioutil.WriteFile(cp+".prov", prov.Bytes(), 0600); err != nil {
+		return nil, err
+	}
+	defer os.Remove(cp + ".prov")
+	if err = verifier.verifyProvenance(cp, cp+".prov"); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(cp)
+}
+
+// setEnv sets the given environment variable, and returns a function that
+// restores its previous value.
+func setEnv(key, value string) func() {
+	prev, ok := os.LookupEnv(key)
+	_ = os.Setenv(key, value)
+	return func() {
+		if ok {
+			_ = os.Setenv(key, prev)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	}
+}
+
+// resolveOCIVersion returns the latest version of the chart at the given OCI
+// reference satisfying the given version constraint.
+func resolveOCIVersion(ctx context.Context, ref, constraint string, creds *repositoryCredentials) (string, error) {
+	if constraint == "" {
+		constraint = "*"
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return "", err
+	}
+
+	tags, err := listOCITags(ctx, ref, creds)
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags of %s: %w", ref, err)
+	}
+
+	var versions []*semver.Version
+	for _, tag := range tags {
+		// "+" is not allowed in tags, and is replaced by "_" when pushing
+		v, err := semver.NewVersion(strings.ReplaceAll(tag, "_", "+"))
+		if err == nil && c.Check(v) {
+			versions = append(versions, v)
+		}
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("no chart version found for %s-%s", ref, constraint)
+	}
+	sort.Sort(sort.Reverse(semver.Collection(versions)))
+	return versions[0].Original(), nil
+}
+
+// listOCITags lists the tags of the given OCI reference, using the
+// distribution API of its registry.
+func listOCITags(ctx context.Context, ref string, creds *repositoryCredentials) ([]string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newOCIHTTPClient(creds)
+	if err != nil {
+		return nil, err
+	}
+	username, password := dockerConfigAuth(creds.DockerConfig, u.Host)
+
+	next := fmt.Sprintf("https://%s/v2/%s/tags/list", u.Host, strings.TrimPrefix(u.Path, "/"))
+	var tags []string
+	var token string
+	for next != "" {
+		res, err := ociGet(ctx, client, next, token, username, password)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode == http.StatusUnauthorized && token == "" {
+			challenge := res.Header.Get("WWW-Authenticate")
+			res.Body.Close()
+			if token, err = ociToken(ctx, client, challenge, username, password); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var list struct {
+			Tags []string `json:"tags"`
+		}
+		err = decodeOCIResponse(res, &list)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, list.Tags...)
+
+		next = ""
+		if link := ociNextLink(res.Header.Get("Link")); link != "" {
+			if next, err = resolveURL(res.Request.URL, link); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tags, nil
+}
+
+// newOCIHTTPClient returns an HTTP client using the TLS settings of the given
+// credentials.
+func newOCIHTTPClient(creds *repositoryCredentials) (*http.Client, error) {
+	config := &tls.Config{InsecureSkipVerify: creds.InsecureSkipTLSVerify} // #nosec G402 -- opt-in
+	if creds.CAFile != "" {
+		ca, err := ioutil.ReadFile(creds.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		config.RootCAs.AppendCertsFromPEM(ca)
+	}
+	if creds.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(creds.CertFile, creds.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return &http.Client{Transport: transport}, nil
+}
+
+// ociGet gets the given URL, authenticating with the given bearer token or,
+// when there is none, with the given basic auth credentials.
+func ociGet(ctx context.Context, client *http.Client, url, token, username, password string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	return client.Do(req)
+}
+
+// ociToken requests a bearer token as described by the given challenge.
+func ociToken(ctx context.Context, client *http.Client, challenge, username, password string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	if !strings.EqualFold(scheme, "Bearer") || params["realm"] == "" {
+		return "", fmt.Errorf("unauthorized: unsupported challenge %q", challenge)
+	}
+
+	u, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			q.Set(key, params[key])
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	res, err := ociGet(ctx, client, u.String(), "", username, password)
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = decodeOCIResponse(res, &out); err != nil {
+		return "", err
+	}
+	if out.Token != "" {
+		return out.Token, nil
+	}
+	return out.AccessToken, nil
+}
+
+// decodeOCIResponse decodes the JSON body of the given response, and closes it.
+func decodeOCIResponse(res *http.Response, v interface{}) error {
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status from %s: %s", res.Request.URL, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// challengeParamRe matches the parameters of a WWW-Authenticate challenge.
+var challengeParamRe = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// parseChallenge returns the scheme and parameters of the given
+// WWW-Authenticate challenge.
+func parseChallenge(challenge string) (string, map[string]string) {
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	params := make(map[string]string)
+	if len(parts) == 2 {
+		for _, m := range challengeParamRe.FindAllStringSubmatch(parts[1], -1) {
+			params[m[1]] = m[2]
+		}
+	}
+	return parts[0], params
+}
+
+// ociNextLink returns the target of the given Link header, if it refers to
+// the next page of results.
+func ociNextLink(link string) string {
+	if !strings.Contains(link, `rel="next"`) {
+		return ""
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return link[start+1 : end]
+}
+
+func resolveURL(base *url.URL, ref string) (string, error) {
+	u, err := base.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// dockerConfigAuth returns the credentials of the given registry host in the
+// given docker config.
+func dockerConfigAuth(dockerConfig []byte, host string) (string, string) {
+	if len(dockerConfig) == 0 {
+		return "", ""
+	}
+	var config struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(dockerConfig, &config); err != nil {
+		return "", ""
+	}
+	for key, auth := range config.Auths {
+		if key != host && strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://") != host {
+			continue
+		}
+		if auth.Username != "" {
+			return auth.Username, auth.Password
+		}
+		if decoded, err := base64.StdEncoding.DecodeString(auth.Auth); err == nil {
+			if parts := strings.SplitN(string(decoded), ":", 2); len(parts) == 2 {
+				return parts[0], parts[1]
+			}
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,207 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package helm
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"helm.sh/helm/v3/pkg/postrender"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// postRenderer applies the post-render configuration of a component to its
+// rendered manifest, in-process.
+type postRenderer struct {
+	// namespace is the namespace of rendered objects that set none.
+	namespace string
+	opts      *oceanv1beta1.PostRender
+}
+
+var _ postrender.PostRenderer = (*postRenderer)(nil)
+
+// newPostRenderer returns the post-renderer of the given component, or nil
+// when it has no post-render configuration.
+func newPostRenderer(component *oceanv1beta1.OceanComponent, namespace string) postrender.PostRenderer {
+	if component.Spec.PostRender == nil {
+		return nil
+	}
+	return &postRenderer{
+		namespace: namespace,
+		opts:      component.Spec.PostRender,
+	}
+}
+
+// Run implements postrender.PostRenderer.
+func (p *postRenderer) Run(rendered *bytes.Buffer) (*bytes.Buffer, error) {
+	objs, err := decodeManifest(rendered)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range objs {
+		p.applyMetadata(obj)
+	}
+	for i, patch := range p.opts.PatchesStrategicMerge {
+		if err = p.applyStrategicMerge(objs, patch.Raw); err != nil {
+			return nil, fmt.Errorf("patchesStrategicMerge[%d]: %w", i, err)
+		}
+	}
+	for i, patch := range p.opts.PatchesJSON6902 {
+		if err = p.applyJSON6902(objs, patch); err != nil {
+			return nil, fmt.Errorf("patchesJson6902[%d]: %w", i, err)
+		}
+	}
+
+	out := new(bytes.Buffer)
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("---\n")
+		out.Write(data)
+	}
+	return out, nil
+}
+
+// applyMetadata adds the common labels and annotations to the given object.
+func (p *postRenderer) applyMetadata(obj *unstructured.Unstructured) {
+	if len(p.opts.CommonLabels) > 0 {
+		obj.SetLabels(mergeStringMaps(obj.GetLabels(), p.opts.CommonLabels))
+	}
+	if len(p.opts.CommonAnnotations) > 0 {
+		obj.SetAnnotations(mergeStringMaps(obj.GetAnnotations(), p.opts.CommonAnnotations))
+	}
+}
+
+// applyStrategicMerge applies the given strategic merge patch to the object
+// it identifies, which must be rendered.
+func (p *postRenderer) applyStrategicMerge(objs []*unstructured.Unstructured, patch []byte) error {
+	target := new(unstructured.Unstructured)
+	if err := target.UnmarshalJSON(patch); err != nil {
+		return fmt.Errorf("invalid patch: %w", err)
+	}
+	gvk := target.GroupVersionKind()
+
+	for _, obj := range objs {
+		if obj.GroupVersionKind() != gvk || obj.GetName() != target.GetName() ||
+			(target.GetNamespace() != "" && p.namespaceOf(obj) != target.GetNamespace()) {
+			continue
+		}
+
+		orig, err := obj.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		var patched []byte
+		if typed, err := scheme.Scheme.New(gvk); err == nil {
+			patched, err = strategicpatch.StrategicMergePatch(orig, patch, typed)
+			if err != nil {
+				return fmt.Errorf("failed to patch %s %s: %w", gvk.Kind, obj.GetName(), err)
+			}
+		} else {
+			// kinds without patch strategies, such as custom resources
+			if patched, err = jsonpatch.MergePatch(orig, patch); err != nil {
+				return fmt.Errorf("failed to patch %s %s: %w", gvk.Kind, obj.GetName(), err)
+			}
+		}
+		return obj.UnmarshalJSON(patched)
+	}
+	return fmt.Errorf("no rendered %s %s", gvk.Kind, target.GetName())
+}
+
+// applyJSON6902 applies the given JSON patch to the objects it selects, of
+// which there must be at least one.
+func (p *postRenderer) applyJSON6902(objs []*unstructured.Unstructured, patch oceanv1beta1.JSON6902Patch) error {
+	ops, err := yaml.YAMLToJSON([]byte(patch.Patch))
+	if err != nil {
+		return fmt.Errorf("invalid patch: %w", err)
+	}
+	decoded, err := jsonpatch.DecodePatch(ops)
+	if err != nil {
+		return fmt.Errorf("invalid patch: %w", err)
+	}
+
+	target := patch.Target
+	matched := false
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		if gvk.Group != target.Group || gvk.Kind != target.Kind || obj.GetName() != target.Name ||
+			(target.Version != "" && gvk.Version != target.Version) ||
+			(target.Namespace != "" && p.namespaceOf(obj) != target.Namespace) {
+			continue
+		}
+		matched = true
+
+		orig, err := obj.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		patched, err := decoded.Apply(orig)
+		if err != nil {
+			return fmt.Errorf("failed to patch %s %s: %w", gvk.Kind, obj.GetName(), err)
+		}
+		if err = obj.UnmarshalJSON(patched); err != nil {
+			return err
+		}
+	}
+	if !matched {
+		return fmt.Errorf("no rendered %s %s", target.Kind, target.Name)
+	}
+	return nil
+}
+
+// namespaceOf returns the namespace the given object is installed into.
+func (p *postRenderer) namespaceOf(obj *unstructured.Unstructured) string {
+	if ns := obj.GetNamespace(); ns != "" {
+		return ns
+	}
+	return p.namespace
+}
+
+// decodeManifest decodes the objects of the given multi-document manifest,
+// skipping empty documents.
+func decodeManifest(manifest io.Reader) ([]*unstructured.Unstructured, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(manifest))
+	var objs []*unstructured.Unstructured
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+
+		var obj map[string]interface{}
+		if err = yaml.Unmarshal(doc, &obj); err != nil {
+			return nil, fmt.Errorf("failed to decode manifest: %w", err)
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		objs = append(objs, &unstructured.Unstructured{Object: obj})
+	}
+}
+
+// mergeStringMaps returns the entries of b merged over those of a.
+func mergeStringMaps(a, b map[string]string) map[string]string {
+	out := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,165 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/repo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// repositoryCredentials holds the credentials of a chart repository, with
+// its certificates written to files as Helm expects.
+type repositoryCredentials struct {
+	Username              string
+	Password              string
+	CertFile              string
+	KeyFile               string
+	CAFile                string
+	DockerConfig          []byte
+	InsecureSkipTLSVerify bool
+	PassCredentialsAll    bool
+
+	// digest is the digest of the credential material, if any, which
+	// distinguishes the cached indexes and charts fetched with it.
+	digest string
+}
+
+// removeFiles removes the certificate files of the credentials.
+func (c *repositoryCredentials) removeFiles() {
+	for _, path := range []string{c.CertFile, c.KeyFile, c.CAFile} {
+		if path != "" {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// applyToChartPathOptions sets the credentials on the given chart options,
+// which are used to both locate and download charts.
+func (c *repositoryCredentials) applyToChartPathOptions(opts *action.ChartPathOptions) {
+	opts.Username = c.Username
+	opts.Password = c.Password
+	opts.CertFile = c.CertFile
+	opts.KeyFile = c.KeyFile
+	opts.CaFile = c.CAFile
+	opts.InsecureSkipTLSverify = c.InsecureSkipTLSVerify
+	opts.PassCredentialsAll = c.PassCredentialsAll
+}
+
+// applyToEntry sets the credentials on the given repository entry.
+func (c *repositoryCredentials) applyToEntry(entry *repo.Entry) {
+	entry.Username = c.Username
+	entry.Password = c.Password
+	entry.CertFile = c.CertFile
+	entry.KeyFile = c.KeyFile
+	entry.CAFile = c.CAFile
+	entry.InsecureSkipTLSverify = c.InsecureSkipTLSVerify
+	entry.PassCredentialsAll = c.PassCredentialsAll
+}
+
+// getRepositoryCredentials returns the credentials of the repository of the
+// given component, reading its Secret, if any, from the cluster. Certificates
+// are written to new files of the given directory, which the caller removes.
+func (i *Installer) getRepositoryCredentials(ctx context.Context, component *oceanv1beta1.OceanComponent,
+	dir string) (*repositoryCredentials, error) {
+	opts := component.Spec.Repository
+	if opts == nil {
+		return new(repositoryCredentials), nil
+	}
+
+	var secret *corev1.Secret
+	if opts.SecretRef != nil {
+		client, err := i.getKubeClient()
+		if err != nil {
+			return nil, err
+		}
+		secret, err = client.CoreV1().Secrets(component.Namespace).Get(
+			ctx, opts.SecretRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get repository secret %s: %w", opts.SecretRef.Name, err)
+		}
+	}
+
+	return newRepositoryCredentials(opts, secret, dir)
+}
+
+// newRepositoryCredentials returns the credentials described by the given
+// repository options and their Secret, which may be nil. Certificates are
+// written to new files of the given directory.
+func newRepositoryCredentials(opts *oceanv1beta1.RepositoryOptions, secret *corev1.Secret,
+	dir string) (*repositoryCredentials, error) {
+	creds := &repositoryCredentials{
+		InsecureSkipTLSVerify: opts.InsecureSkipTLSVerify,
+		PassCredentialsAll:    opts.PassCredentialsAll,
+	}
+	if secret == nil {
+		return creds, nil
+	}
+
+	creds.digest = credentialsDigest(secret)
+	creds.Username = string(secret.Data[corev1.BasicAuthUsernameKey])
+	creds.Password = string(secret.Data[corev1.BasicAuthPasswordKey])
+	if (creds.Username == "") != (creds.Password == "") {
+		return nil, fmt.Errorf("repository secret %s must hold both %q and %q, or neither",
+			secret.Name, corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey)
+	}
+
+	// registries are logged into using a docker config
+	creds.DockerConfig = secret.Data[corev1.DockerConfigJsonKey]
+
+	cert, key := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if (len(cert) == 0) != (len(key) == 0) {
+		return nil, fmt.Errorf("repository secret %s must hold both %q and %q, or neither",
+			secret.Name, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+
+	files := []struct {
+		key  string
+		path *string
+	}{
+		{corev1.TLSCertKey, &creds.CertFile},
+		{corev1.TLSPrivateKeyKey, &creds.KeyFile},
+		{corev1.ServiceAccountRootCAKey, &creds.CAFile},
+	}
+	for _, f := range files {
+		data := secret.Data[f.key]
+		if len(data) == 0 {
+			continue
+		}
+		path, err := writeTempFile(dir, f.key+"-", data)
+		if err != nil {
+			creds.removeFiles()
+			return nil, fmt.Errorf("unable to write %s: %w", f.key, err)
+		}
+		*f.path = path
+	}
+
+	return creds, nil
+}
+
+// credentialsDigest returns the digest of the credential material held by the
+// given repository Secret.
+func credentialsDigest(secret *corev1.Secret) string {
+	h := sha256.New()
+	for _, key := range []string{
+		corev1.BasicAuthUsernameKey,
+		corev1.BasicAuthPasswordKey,
+		corev1.TLSCertKey,
+		corev1.TLSPrivateKeyKey,
+		corev1.ServiceAccountRootCAKey,
+		corev1.DockerConfigJsonKey,
+	} {
+		data := secret.Data[key]
+		fmt.Fprintf(h, "%s:%d:", key, len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
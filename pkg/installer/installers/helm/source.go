@@ -0,0 +1,127 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// loadSourceChart loads the chart of the given component from its source,
+// without network access other than to the cluster. Archives are verified by
+// the given verifier.
+func (i *Installer) loadSourceChart(ctx context.Context, component *oceanv1beta1.OceanComponent,
+	verifier *chartVerifier) (*chart.Chart, error) {
+	src := component.Spec.Source
+	if verifier.verifiesProvenance() {
+		return nil, fmt.Errorf("%w: charts with a source have no provenance", installer.ErrVerificationFailed)
+	}
+
+	var data []byte
+	if src.Path != "" {
+		path := strings.TrimPrefix(src.Path, "file://")
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load chart %s: %w", path, err)
+		}
+		if fi.IsDir() {
+			if component.Spec.Digest != "" {
+				return nil, fmt.Errorf("%w: chart directory %s has no digest", installer.ErrVerificationFailed, path)
+			}
+			chrt, err := loader.Load(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load chart %s: %w", path, err)
+			}
+			return chrt, nil
+		}
+		if data, err = ioutil.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to load chart %s: %w", path, err)
+		}
+	} else {
+		var err error
+		if data, err = i.getSourceArchive(ctx, component.Namespace, src); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := verifier.verifyDigest(data); err != nil {
+		return nil, fmt.Errorf("failed to verify chart archive: %w", err)
+	}
+	chrt, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart archive: %w", err)
+	}
+	return chrt, nil
+}
+
+// getSourceArchive returns the chart archive held by the ConfigMap or Secret,
+// in the given namespace, of the given source.
+func (i *Installer) getSourceArchive(ctx context.Context, namespace string, src *oceanv1beta1.ChartSource) ([]byte, error) {
+	client, err := i.getKubeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case src.ConfigMap != nil:
+		cm, err := client.CoreV1().ConfigMaps(namespace).Get(
+			ctx, src.ConfigMap.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get chart configmap %s: %w", src.ConfigMap.Name, err)
+		}
+		if data, ok := cm.BinaryData[src.ConfigMap.Key]; ok {
+			return data, nil
+		}
+		if data, ok := cm.Data[src.ConfigMap.Key]; ok {
+			return []byte(data), nil
+		}
+		return nil, fmt.Errorf("chart configmap %s has no key %q", src.ConfigMap.Name, src.ConfigMap.Key)
+	case src.Secret != nil:
+		secret, err := client.CoreV1().Secrets(namespace).Get(
+			ctx, src.Secret.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get chart secret %s: %w", src.Secret.Name, err)
+		}
+		if data, ok := secret.Data[src.Secret.Key]; ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf("chart secret %s has no key %q", src.Secret.Name, src.Secret.Key)
+	default:
+		return nil, fmt.Errorf("invalid chart source: no location set")
+	}
+}
+
+// resolveSourceVersion returns the version of the chart at the source of the
+// given component, which must satisfy the version constraint of the component.
+func (i *Installer) resolveSourceVersion(ctx context.Context, component *oceanv1beta1.OceanComponent) (string, error) {
+	// verified when loaded for installation
+	chrt, err := i.loadSourceChart(ctx, component, nil)
+	if err != nil {
+		return "", err
+	}
+
+	version := chrt.Metadata.Version
+	if constraint := component.Spec.Version; constraint != "" && constraint != version {
+		c, err := semver.NewConstraint(constraint)
+		if err != nil {
+			return "", err
+		}
+		v, err := semver.NewVersion(version)
+		if err != nil || !c.Check(v) {
+			return "", fmt.Errorf("version %s of chart %s does not satisfy %q",
+				version, chrt.Name(), constraint)
+		}
+	}
+	return version, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"helm.sh/helm/v3/pkg/provenance"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// chartVerifier verifies the digest and provenance of chart archives. A nil
+// verifier verifies nothing.
+type chartVerifier struct {
+	// keyring is the path of the public keyring charts must be signed with,
+	// if any.
+	keyring string
+	// keyringDigest is the digest of the keyring, which distinguishes the
+	// cached charts it verified.
+	keyringDigest string
+	// digest is the pinned SHA-256 digest of chart archives, if any.
+	digest string
+}
+
+// getChartVerifier returns the verifier of the chart of the given component,
+// reading its keyring, if any, from the cluster. The keyring is written to a
+// new file of the given directory, which the caller removes.
+func (i *Installer) getChartVerifier(ctx context.Context, component *oceanv1beta1.OceanComponent,
+	dir string) (*chartVerifier, error) {
+	v := &chartVerifier{digest: strings.TrimPrefix(component.Spec.Digest, "sha256:")}
+	if component.Spec.Verify == nil {
+		return v, nil
+	}
+
+	ref := component.Spec.Verify.KeyringSecretRef
+	client, err := i.getKubeClient()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := client.CoreV1().Secrets(component.Namespace).Get(
+		ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keyring secret %s: %w", ref.Name, err)
+	}
+	keyring, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("keyring secret %s has no key %q", ref.Name, ref.Key)
+	}
+
+	if v.keyring, err = writeTempFile(dir, "keyring-*.gpg", keyring); err != nil {
+		return nil, fmt.Errorf("unable to write keyring: %w", err)
+	}
+	v.keyringDigest = sha256Digest(keyring)
+	return v, nil
+}
+
+// removeFiles removes the keyring file of the verifier.
+func (v *chartVerifier) removeFiles() {
+	if v != nil && v.keyring != "" {
+		_ = os.Remove(v.keyring)
+	}
+}
+
+// verifiesProvenance reports whether the verifier verifies provenance files.
+func (v *chartVerifier) verifiesProvenance() bool {
+	return v != nil && v.keyring != ""
+}
+
+// verifyDigest verifies that the given chart archive has the pinned digest.
+func (v *chartVerifier) verifyDigest(data []byte) error {
+	if v == nil || v.digest == "" {
+		return nil
+	}
+	return verifyDigest(data, v.digest)
+}
+
+// verifyProvenance verifies the given chart archive against the given
+// provenance file, using the keyring of the verifier.
+func (v *chartVerifier) verifyProvenance(chartPath, provPath string) error {
+	if !v.verifiesProvenance() {
+		return nil
+	}
+	sig, err := provenance.NewFromKeyring(v.keyring, "")
+	if err != nil {
+		return fmt.Errorf("failed to load keyring: %w", err)
+	}
+	// the provenance file lists the archive by its file name
+	if _, err = sig.Verify(chartPath, provPath); err != nil {
+		return fmt.Errorf("%w: provenance of %s: %v", installer.ErrVerificationFailed,
+			filepath.Base(chartPath), err)
+	}
+	return nil
+}
+
+// verifyDigest verifies that the given data has the given SHA-256 digest, if
+// any.
+func verifyDigest(data []byte, digest string) error {
+	if digest == "" {
+		return nil
+	}
+	if actual := sha256Digest(data); actual != digest {
+		return fmt.Errorf("%w: digest mismatch: expected %s, got %s",
+			installer.ErrVerificationFailed, digest, actual)
+	}
+	return nil
+}
+
+// sha256Digest returns the hex-encoded SHA-256 digest of the given data.
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -4,4 +4,6 @@ package installers
 
 import (
 	_ "github.com/spotinst/ocean-operator/pkg/installer/installers/helm"
+	_ "github.com/spotinst/ocean-operator/pkg/installer/installers/kustomize"
+	_ "github.com/spotinst/ocean-operator/pkg/installer/installers/manifest"
 )
@@ -0,0 +1,167 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package kustomize
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/spotinst/ocean-operator/pkg/installer/installers/manifest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	// downloadTimeout is the time to wait for an archive to be downloaded.
+	downloadTimeout = time.Minute
+	// maxArchiveSize is the maximum total size, in bytes, of the files of an
+	// archive.
+	maxArchiveSize = 16 << 20
+)
+
+func init() {
+	installer.MustRegister(oceanv1beta1.OceanComponentTypeKustomize.String(),
+		func(options *installer.InstallerOptions) (installer.Installer, error) {
+			return NewInstaller(options), nil
+		})
+}
+
+// NewInstaller returns a manifest installer whose manifests are built from
+// kustomizations.
+func NewInstaller(options *installer.InstallerOptions) *manifest.Installer {
+	i := manifest.NewInstaller(options)
+	i.Render = Build
+	return i
+}
+
+// Build is a manifest.RenderFunc returning the manifest built from the
+// kustomization located by the Spec.Kustomize of the given component.
+// Kustomize plugins, which run external programs, are disabled.
+func Build(ctx context.Context, client kubernetes.Interface, component *oceanv1beta1.OceanComponent) ([]byte, error) {
+	src := component.Spec.Kustomize
+	if src == nil {
+		return nil, fmt.Errorf("component %s has no kustomization", component.Name)
+	}
+
+	var (
+		fs  filesys.FileSystem
+		dir string
+		err error
+	)
+	switch {
+	case src.Path != "":
+		fs, dir = filesys.MakeFsOnDisk(), strings.TrimPrefix(src.Path, "file://")
+	case src.ConfigMap != nil:
+		fs, dir = filesys.MakeFsInMemory(), "/"
+		err = loadConfigMap(ctx, client, component.Namespace, src.ConfigMap.Name, fs)
+	default:
+		fs, dir = filesys.MakeFsInMemory(), path.Join("/", src.Dir)
+		err = loadArchive(ctx, src.URL, fs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resources, err := k.Run(fs, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kustomization: %w", err)
+	}
+	return resources.AsYaml()
+}
+
+// loadConfigMap writes the keys of the given ConfigMap as files of the root
+// directory of the given file system.
+func loadConfigMap(ctx context.Context, client kubernetes.Interface, namespace, name string, fs filesys.FileSystem) error {
+	cm, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get kustomization configmap %s: %w", name, err)
+	}
+	for key, value := range cm.Data {
+		if err = fs.WriteFile(path.Join("/", key), []byte(value)); err != nil {
+			return err
+		}
+	}
+	for key, value := range cm.BinaryData {
+		if err = fs.WriteFile(path.Join("/", key), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadArchive downloads the gzipped tar archive at the given URL, and
+// extracts its regular files into the given file system.
+func loadArchive(ctx context.Context, url string, fs filesys.FileSystem) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: downloadTimeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download archive %s: %w", url, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download archive %s: %s", url, res.Status)
+	}
+
+	if err = extractArchive(res.Body, fs); err != nil {
+		return fmt.Errorf("failed to extract archive %s: %w", url, err)
+	}
+	return nil
+}
+
+// extractArchive extracts the regular files of the given gzipped tar archive
+// into the given file system. Paths are confined to its root directory.
+func extractArchive(r io.Reader, fs filesys.FileSystem) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	var size int64
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if size += header.Size; size > maxArchiveSize {
+			return fmt.Errorf("archive is larger than %d bytes", maxArchiveSize)
+		}
+
+		data, err := ioutil.ReadAll(io.LimitReader(tr, header.Size))
+		if err != nil {
+			return err
+		}
+		name := path.Join("/", header.Name) // cleaned, so ".." stays at the root
+		if err = fs.MkdirAll(path.Dir(name)); err != nil {
+			return err
+		}
+		if err = fs.WriteFile(name, data); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package kustomize
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestBuild(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: oceanv1beta1.NamespaceSystem},
+		Data: map[string]string{
+			"kustomization.yaml":  "resources:\n- serviceaccount.yaml\ncommonLabels:\n  team: ocean\n",
+			"serviceaccount.yaml": "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: foo\n",
+		},
+	}
+	client := fake.NewSimpleClientset(cm)
+
+	newComponent := func(src *oceanv1beta1.KustomizeSource) *oceanv1beta1.OceanComponent {
+		return &oceanv1beta1.OceanComponent{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: oceanv1beta1.NamespaceSystem},
+			Spec: oceanv1beta1.OceanComponentSpec{
+				Type:      oceanv1beta1.OceanComponentTypeKustomize,
+				Name:      "foo",
+				Kustomize: src,
+			},
+		}
+	}
+
+	t.Run("whenConfigMap", func(tt *testing.T) {
+		out, err := Build(context.TODO(), client, newComponent(&oceanv1beta1.KustomizeSource{
+			ConfigMap: &corev1.LocalObjectReference{Name: "foo"},
+		}))
+		assert.NoError(tt, err)
+		assert.Contains(tt, string(out), "kind: ServiceAccount")
+		assert.Contains(tt, string(out), "team: ocean")
+	})
+
+	t.Run("whenConfigMapMissing", func(tt *testing.T) {
+		_, err := Build(context.TODO(), client, newComponent(&oceanv1beta1.KustomizeSource{
+			ConfigMap: &corev1.LocalObjectReference{Name: "bar"},
+		}))
+		assert.Error(tt, err)
+	})
+}
+
+func TestExtractArchive(t *testing.T) {
+	buf := new(bytes.Buffer)
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{
+		"overlays/prod/kustomization.yaml": "resources: []\n",
+		"../../etc/passwd":                 "root\n",
+	} {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{
+			Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+
+	fs := filesys.MakeFsInMemory()
+	assert.NoError(t, extractArchive(buf, fs))
+	assert.True(t, fs.Exists("/overlays/prod/kustomization.yaml"))
+	assert.True(t, fs.Exists("/etc/passwd")) // confined to the root
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+)
+
+// fieldManager is the field manager of the objects applied by the installer.
+const fieldManager = "ocean-operator-manifest"
+
+// clients holds the clients of the cluster used by the installer.
+type clients struct {
+	kube    kubernetes.Interface
+	dynamic dynamic.Interface
+	mapper  *restmapper.DeferredDiscoveryRESTMapper
+}
+
+// getClients returns the clients of the cluster.
+func (i *Installer) getClients() (*clients, error) {
+	config, err := i.ClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	kube, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	discovery, err := i.ClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	return &clients{
+		kube:    kube,
+		dynamic: dyn,
+		mapper:  restmapper.NewDeferredDiscoveryRESTMapper(discovery),
+	}, nil
+}
+
+// storage returns the storage of the releases of the given namespace.
+func (c *clients) storage(namespace string) *releaseStorage {
+	return &releaseStorage{client: c.kube, namespace: namespace}
+}
+
+// inventory returns the references of the given objects, setting the given
+// namespace on namespaced objects that have none.
+func (c *clients) inventory(objs []*unstructured.Unstructured, namespace string) ([]objectRef, error) {
+	refs := make([]objectRef, 0, len(objs))
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		mapping, err := c.restMapping(gvk.GroupKind(), gvk.Version)
+		switch {
+		case meta.IsNoMatchError(err):
+			// the kind may be defined by a CRD of the manifest, and its
+			// scope is only known once applied
+		case err != nil:
+			return nil, err
+		case mapping.Scope.Name() == meta.RESTScopeNameNamespace && obj.GetNamespace() == "":
+			obj.SetNamespace(namespace)
+		case mapping.Scope.Name() == meta.RESTScopeNameRoot:
+			obj.SetNamespace("")
+		}
+		refs = append(refs, objectRef{
+			Group:     gvk.Group,
+			Version:   gvk.Version,
+			Kind:      gvk.Kind,
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		})
+	}
+	return refs, nil
+}
+
+// apply applies the given objects, whose references are given, with
+// server-side apply. Objects defining kinds, such as CRDs, are applied first,
+// and the references of namespaced objects of kinds they define are given
+// the given namespace when they have none.
+func (c *clients) apply(ctx context.Context, objs []*unstructured.Unstructured, refs []objectRef, namespace string, dryRun bool) error {
+	force := true
+	opts := metav1.PatchOptions{FieldManager: fieldManager, Force: &force}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	for _, i := range applyOrder(refs) {
+		obj := objs[i]
+		mapping, err := c.restMapping(obj.GroupVersionKind().GroupKind(), refs[i].Version)
+		if err != nil {
+			return fmt.Errorf("unable to apply %s: %w", refs[i], err)
+		}
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace && refs[i].Namespace == "" {
+			refs[i].Namespace = namespace
+			obj.SetNamespace(namespace)
+		}
+
+		data, err := obj.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		resource := c.resourceFor(mapping, refs[i].Namespace)
+		if _, err = resource.Patch(ctx, refs[i].Name, types.ApplyPatchType, data, opts); err != nil {
+			return fmt.Errorf("unable to apply %s: %w", refs[i], err)
+		}
+	}
+	return nil
+}
+
+// prune deletes the given objects that are not kept, in the reverse order of
+// application. Objects that no longer exist are ignored.
+func (c *clients) prune(ctx context.Context, refs, keep []objectRef, dryRun bool) error {
+	kept := make(map[objectRef]bool, len(keep))
+	for _, ref := range keep {
+		kept[ref.key()] = true
+	}
+	opts := metav1.DeleteOptions{}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	order := applyOrder(refs)
+	for j := len(order) - 1; j >= 0; j-- {
+		ref := refs[order[j]]
+		if kept[ref.key()] {
+			continue
+		}
+		resource, err := c.resource(ref)
+		if meta.IsNoMatchError(err) {
+			continue // the kind is gone, and its objects with it
+		}
+		if err != nil {
+			return fmt.Errorf("unable to delete %s: %w", ref, err)
+		}
+		if err = resource.Delete(ctx, ref.Name, opts); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete %s: %w", ref, err)
+		}
+	}
+	return nil
+}
+
+// resource returns the client of the resource of the given object.
+func (c *clients) resource(ref objectRef) (dynamic.ResourceInterface, error) {
+	mapping, err := c.restMapping(schema.GroupKind{Group: ref.Group, Kind: ref.Kind}, ref.Version)
+	if err != nil {
+		return nil, err
+	}
+	return c.resourceFor(mapping, ref.Namespace), nil
+}
+
+// resourceFor returns the client of the resource of the given mapping, in
+// the given namespace if it is namespaced.
+func (c *clients) resourceFor(mapping *meta.RESTMapping, namespace string) dynamic.ResourceInterface {
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		return c.dynamic.Resource(mapping.Resource)
+	}
+	return c.dynamic.Resource(mapping.Resource).Namespace(namespace)
+}
+
+// restMapping returns the REST mapping of the given kind, discovering the
+// resources of the cluster again if it is unknown, since it may have just
+// been defined.
+func (c *clients) restMapping(gk schema.GroupKind, version string) (*meta.RESTMapping, error) {
+	mapping, err := c.mapper.RESTMapping(gk, version)
+	if meta.IsNoMatchError(err) {
+		c.mapper.Reset()
+		mapping, err = c.mapper.RESTMapping(gk, version)
+	}
+	return mapping, err
+}
+
+// applyOrder returns the indices of the given objects in the order they are
+// applied: namespaces and CRDs first, then the others in manifest order.
+func applyOrder(refs []objectRef) []int {
+	var first, rest []int
+	for i, ref := range refs {
+		if (ref.Group == "" && ref.Kind == "Namespace") ||
+			(ref.Group == "apiextensions.k8s.io" && ref.Kind == "CustomResourceDefinition") {
+			first = append(first, i)
+		} else {
+			rest = append(rest, i)
+		}
+	}
+	return append(first, rest...)
+}
@@ -0,0 +1,291 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/spotinst/ocean-operator/pkg/log"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+)
+
+// recordTimeout is the time to wait for a revision to be recorded, regardless
+// of the deadline of its deployment.
+const recordTimeout = 30 * time.Second
+
+func init() {
+	installer.MustRegister(oceanv1beta1.OceanComponentTypeManifest.String(),
+		func(options *installer.InstallerOptions) (installer.Installer, error) {
+			return NewInstaller(options), nil
+		})
+}
+
+// RenderFunc returns the multi-document YAML manifest of the given component,
+// using the given client of the cluster.
+type RenderFunc func(ctx context.Context, client kubernetes.Interface, component *oceanv1beta1.OceanComponent) ([]byte, error)
+
+// Installer installs components whose manifest is a multi-document YAML file,
+// applying its objects with server-side apply. The objects of each revision
+// of a release are recorded in a Secret, so that objects removed from the
+// manifest are pruned. Other installers may reuse it with their own Render
+// function.
+type Installer struct {
+	ClientGetter genericclioptions.RESTClientGetter
+	Namespace    string
+	DryRun       bool
+	Log          log.Logger
+	// Render renders the manifest of components, which is located by their
+	// Spec.Manifest by default.
+	Render RenderFunc
+}
+
+// NewInstaller returns a Installer.
+func NewInstaller(options *installer.InstallerOptions) *Installer {
+	return &Installer{
+		ClientGetter: options.ClientGetter,
+		Namespace:    options.Namespace,
+		DryRun:       options.DryRun,
+		Log:          options.Log,
+		Render:       LoadManifest,
+	}
+}
+
+func (i *Installer) Get(ctx context.Context, name string) (*installer.Release, error) {
+	clients, err := i.getClients()
+	if err != nil {
+		return nil, err
+	}
+	rel, err := clients.storage(i.Namespace).last(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return rel.Release, nil
+}
+
+func (i *Installer) Install(ctx context.Context, component *oceanv1beta1.OceanComponent) (*installer.Release, error) {
+	clients, err := i.getClients()
+	if err != nil {
+		return nil, err
+	}
+
+	releaseName := component.ReleaseName()
+	storage := clients.storage(i.Namespace)
+	rel, err := storage.last(ctx, releaseName)
+	if err != nil && !installer.IsReleaseNotFound(err) {
+		return nil, fmt.Errorf("existing release check failed: %w", err)
+	} else if rel != nil {
+		i.Log.Info("release already exists", "name", releaseName)
+		return rel.Release, nil
+	}
+
+	manifest, err := i.loadManifest(ctx, clients, component)
+	if err != nil {
+		return nil, err
+	}
+	rel, err = i.deploy(ctx, clients, component, manifest, nil, "Install complete")
+	if err != nil {
+		return nil, fmt.Errorf("installation error: %w", err)
+	}
+
+	i.Log.Info("installed", "name", rel.Name)
+	return rel.Release, nil
+}
+
+func (i *Installer) Uninstall(ctx context.Context, component *oceanv1beta1.OceanComponent) error {
+	clients, err := i.getClients()
+	if err != nil {
+		return err
+	}
+
+	releaseName := component.ReleaseName()
+	storage := clients.storage(i.Namespace)
+	rel, err := storage.last(ctx, releaseName)
+	if err != nil {
+		if installer.IsReleaseNotFound(err) {
+			i.Log.Info("release already uninstalled", "name", releaseName)
+			return nil
+		}
+		return fmt.Errorf("uninstallation error: %w", err)
+	}
+
+	if err = clients.prune(ctx, rel.Inventory, nil, i.DryRun); err != nil {
+		return fmt.Errorf("uninstallation error: %w", err)
+	}
+	if !i.DryRun {
+		if err = storage.delete(ctx, releaseName); err != nil {
+			return fmt.Errorf("uninstallation error: %w", err)
+		}
+	}
+
+	i.Log.Info("uninstalled", "name", releaseName)
+	return nil
+}
+
+func (i *Installer) Upgrade(ctx context.Context, component *oceanv1beta1.OceanComponent) (*installer.Release, error) {
+	clients, err := i.getClients()
+	if err != nil {
+		return nil, err
+	}
+
+	prev, err := clients.storage(i.Namespace).last(ctx, component.ReleaseName())
+	if err != nil {
+		return nil, fmt.Errorf("upgrade error: %w", err)
+	}
+	manifest, err := i.loadManifest(ctx, clients, component)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := i.deploy(ctx, clients, component, manifest, prev, "Upgrade complete")
+	if err != nil {
+		return nil, fmt.Errorf("upgrade error: %w", err)
+	}
+
+	i.Log.Info("upgraded", "name", rel.Name)
+	return rel.Release, nil
+}
+
+func (i *Installer) Rollback(ctx context.Context, component *oceanv1beta1.OceanComponent, revision int) (*installer.Release, error) {
+	clients, err := i.getClients()
+	if err != nil {
+		return nil, err
+	}
+
+	releaseName := component.ReleaseName()
+	history, err := clients.storage(i.Namespace).history(ctx, releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("rollback error: %w", err)
+	}
+	var target *storedRelease
+	for _, rel := range history {
+		if rel.Revision == revision {
+			target = rel
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("rollback error: release %s has no revision %d", releaseName, revision)
+	}
+
+	// the target revision is deployed again as a new revision
+	rollback := component.DeepCopy()
+	rollback.Spec.Version = target.Version
+	rel, err := i.deploy(ctx, clients, rollback, target.Manifest, history[len(history)-1],
+		fmt.Sprintf("Rollback to %d", revision))
+	if err != nil {
+		return nil, fmt.Errorf("rollback error: %w", err)
+	}
+
+	i.Log.Info("rolled back", "name", rel.Name, "revision", revision)
+	return rel.Release, nil
+}
+
+func (i *Installer) History(ctx context.Context, name string) ([]*installer.Release, error) {
+	clients, err := i.getClients()
+	if err != nil {
+		return nil, err
+	}
+
+	rels, err := clients.storage(i.Namespace).history(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]*installer.Release, 0, len(rels))
+	for _, rel := range rels {
+		history = append(history, rel.Release)
+	}
+	return history, nil
+}
+
+func (i *Installer) IsUpgrade(ctx context.Context, component *oceanv1beta1.OceanComponent, release *installer.Release) bool {
+	if component.Spec.Version != release.Version {
+		return true
+	}
+
+	clients, err := i.getClients()
+	if err != nil {
+		i.Log.Error(err, "failed to get clients")
+		return true // fail properly later
+	}
+	manifest, err := i.loadManifest(ctx, clients, component)
+	if err != nil {
+		i.Log.Error(err, "failed to load manifest")
+		return true // fail properly later
+	}
+
+	if manifest != release.Manifest {
+		i.Log.V(5).Info("upgrade is required", "reason", "manifest changed")
+		return true
+	}
+
+	return false
+}
+
+// ResolveVersion returns the version of the given component as is, since
+// manifests are not versioned.
+func (i *Installer) ResolveVersion(ctx context.Context, component *oceanv1beta1.OceanComponent) (string, error) {
+	return component.Spec.Version, nil
+}
+
+// deploy applies the given manifest of the given component as a new revision
+// of its release, following the given previous revision, if any, whose
+// objects missing from the manifest are pruned. The new revision is recorded
+// even when the manifest fails to apply, including when the given context
+// expires, so that the objects applied so far are pruned later on.
+func (i *Installer) deploy(ctx context.Context, clients *clients, component *oceanv1beta1.OceanComponent,
+	manifest string, prev *storedRelease, description string) (*storedRelease, error) {
+	objs, err := decodeManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	inventory, err := clients.inventory(objs, i.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	rel := &storedRelease{
+		Release: &installer.Release{
+			Name:        component.ReleaseName(),
+			Version:     component.Spec.Version,
+			Revision:    1,
+			Status:      installer.ReleaseStatusDeployed,
+			Description: description,
+			Manifest:    manifest,
+		},
+		Inventory: inventory,
+	}
+	if prev != nil {
+		rel.Revision = prev.Revision + 1
+	}
+
+	applyErr := clients.apply(ctx, objs, inventory, i.Namespace, i.DryRun)
+	if applyErr == nil && prev != nil {
+		applyErr = clients.prune(ctx, prev.Inventory, inventory, i.DryRun)
+	}
+	if applyErr != nil {
+		rel.Status = installer.ReleaseStatusFailed
+		rel.Description = applyErr.Error()
+		// objects of the previous revision may be left over
+		if prev != nil {
+			rel.Inventory = mergeInventories(inventory, prev.Inventory)
+		}
+	}
+
+	if !i.DryRun {
+		recordCtx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+		defer cancel()
+		if err = clients.storage(i.Namespace).append(recordCtx, rel); err != nil {
+			if applyErr != nil {
+				return nil, fmt.Errorf("%v (failed to record release: %w)", applyErr, err)
+			}
+			return nil, fmt.Errorf("failed to record release: %w", err)
+		}
+	}
+	if applyErr != nil {
+		return nil, applyErr
+	}
+	return rel, nil
+}
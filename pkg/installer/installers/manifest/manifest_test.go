@@ -0,0 +1,109 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package manifest
+
+import (
+	"context"
+	"testing"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testManifest = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: foo
+---
+# empty
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+`
+
+func TestLoadManifest(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: oceanv1beta1.NamespaceSystem},
+		Data:       map[string]string{"manifest.yaml": testManifest},
+	}
+	c := &clients{kube: fake.NewSimpleClientset(cm)}
+	i := NewInstaller(&installer.InstallerOptions{})
+
+	newComponent := func(src *oceanv1beta1.ManifestSource) *oceanv1beta1.OceanComponent {
+		return &oceanv1beta1.OceanComponent{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: oceanv1beta1.NamespaceSystem},
+			Spec: oceanv1beta1.OceanComponentSpec{
+				Type:     oceanv1beta1.OceanComponentTypeManifest,
+				Name:     "foo",
+				Manifest: src,
+			},
+		}
+	}
+
+	t.Run("whenInline", func(tt *testing.T) {
+		manifest, err := i.loadManifest(context.TODO(), c, newComponent(&oceanv1beta1.ManifestSource{Inline: testManifest}))
+		assert.NoError(tt, err)
+		objs, err := decodeManifest(manifest)
+		assert.NoError(tt, err)
+		assert.Len(tt, objs, 2)
+	})
+
+	t.Run("whenConfigMapEqualsInline", func(tt *testing.T) {
+		inline, err := i.loadManifest(context.TODO(), c, newComponent(&oceanv1beta1.ManifestSource{Inline: testManifest}))
+		assert.NoError(tt, err)
+		fromConfigMap, err := i.loadManifest(context.TODO(), c, newComponent(&oceanv1beta1.ManifestSource{
+			ConfigMap: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "foo"},
+				Key:                  "manifest.yaml",
+			},
+		}))
+		assert.NoError(tt, err)
+		assert.Equal(tt, inline, fromConfigMap)
+	})
+
+	t.Run("whenObjectUnnamed", func(tt *testing.T) {
+		_, err := i.loadManifest(context.TODO(), c, newComponent(&oceanv1beta1.ManifestSource{Inline: "apiVersion: v1\nkind: ConfigMap\n"}))
+		assert.Error(tt, err)
+	})
+}
+
+func TestReleaseStorage(t *testing.T) {
+	s := &releaseStorage{client: fake.NewSimpleClientset(), namespace: oceanv1beta1.NamespaceSystem}
+
+	_, err := s.last(context.TODO(), "foo")
+	assert.True(t, installer.IsReleaseNotFound(err))
+
+	for rev := 1; rev <= maxHistory+2; rev++ {
+		assert.NoError(t, s.append(context.TODO(), &storedRelease{
+			Release: &installer.Release{
+				Name:     "foo",
+				Revision: rev,
+				Status:   installer.ReleaseStatusDeployed,
+			},
+			Inventory: []objectRef{{Version: "v1", Kind: "ServiceAccount", Namespace: "spot-system", Name: "foo"}},
+		}))
+	}
+
+	history, err := s.history(context.TODO(), "foo")
+	assert.NoError(t, err)
+	assert.Len(t, history, maxHistory)
+	assert.Equal(t, 3, history[0].Revision)
+	assert.Equal(t, installer.ReleaseStatusSuperseded, history[0].Status)
+
+	last, err := s.last(context.TODO(), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, maxHistory+2, last.Revision)
+	assert.Equal(t, installer.ReleaseStatusDeployed, last.Status)
+	assert.Len(t, last.Inventory, 1)
+
+	assert.NoError(t, s.delete(context.TODO(), "foo"))
+	_, err = s.last(context.TODO(), "foo")
+	assert.True(t, installer.IsReleaseNotFound(err))
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package manifest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// downloadTimeout is the time to wait for a manifest to be downloaded.
+const downloadTimeout = time.Minute
+
+// loadManifest renders the manifest of the given component, normalized so
+// that equal manifests are equal strings.
+func (i *Installer) loadManifest(ctx context.Context, c *clients, component *oceanv1beta1.OceanComponent) (string, error) {
+	data, err := i.Render(ctx, c.kube, component)
+	if err != nil {
+		return "", err
+	}
+	objs, err := decodeManifest(string(data))
+	if err != nil {
+		return "", err
+	}
+	return encodeManifest(objs)
+}
+
+// LoadManifest is a RenderFunc returning the manifest located by the
+// Spec.Manifest of the given component.
+func LoadManifest(ctx context.Context, client kubernetes.Interface, component *oceanv1beta1.OceanComponent) ([]byte, error) {
+	src := component.Spec.Manifest
+	switch {
+	case src == nil:
+		return nil, fmt.Errorf("component %s has no manifest", component.Name)
+	case src.URL != "":
+		return downloadManifest(ctx, src.URL)
+	case src.ConfigMap != nil:
+		ref := src.ConfigMap
+		cm, err := client.CoreV1().ConfigMaps(component.Namespace).Get(
+			ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get manifest configmap %s: %w", ref.Name, err)
+		}
+		value, ok := cm.Data[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("manifest configmap %s has no key %q", ref.Name, ref.Key)
+		}
+		return []byte(value), nil
+	default:
+		return []byte(src.Inline), nil
+	}
+}
+
+// downloadManifest downloads the manifest at the given URL.
+func downloadManifest(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: downloadTimeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download manifest %s: %w", url, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download manifest %s: %s", url, res.Status)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+// decodeManifest decodes the objects of the given multi-document manifest,
+// skipping empty documents. Lists are expanded into their items.
+func decodeManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(manifest)))
+	var objs []*unstructured.Unstructured
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+
+		var obj map[string]interface{}
+		if err = yaml.Unmarshal(doc, &obj); err != nil {
+			return nil, fmt.Errorf("failed to decode manifest: %w", err)
+		}
+		if len(obj) == 0 {
+			continue
+		}
+
+		u := &unstructured.Unstructured{Object: obj}
+		if u.IsList() {
+			list, err := u.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode manifest: %w", err)
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+			continue
+		}
+		if u.GetKind() == "" || u.GetName() == "" {
+			return nil, fmt.Errorf("invalid manifest: objects must have a kind and a name")
+		}
+		objs = append(objs, u)
+	}
+}
+
+// encodeManifest encodes the given objects as a multi-document manifest.
+func encodeManifest(objs []*unstructured.Unstructured) (string, error) {
+	var b strings.Builder
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString("---\n")
+		b.Write(data)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// storagePrefix prefixes the names of the Secrets recording releases.
+	storagePrefix = "ocean.manifest.v1."
+	// storageType is the type of the Secrets recording releases.
+	storageType corev1.SecretType = "ocean.spot.io/manifest.v1"
+	// storageKey is the data key of the revisions of a release.
+	storageKey = "releases"
+	// maxHistory is the maximum number of revisions recorded per release.
+	maxHistory = 10
+)
+
+// objectRef identifies an applied object.
+type objectRef struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+func (x objectRef) String() string {
+	if x.Namespace == "" {
+		return fmt.Sprintf("%s %s", x.Kind, x.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", x.Kind, x.Namespace, x.Name)
+}
+
+// key identifies the object regardless of its version.
+func (x objectRef) key() objectRef {
+	x.Version = ""
+	return x
+}
+
+// storedRelease is a revision of a release, with the inventory of the objects
+// it applied.
+type storedRelease struct {
+	*installer.Release
+	Inventory []objectRef `json:"inventory,omitempty"`
+}
+
+// releaseStorage records the revisions of releases in Secrets of a namespace,
+// one per release.
+type releaseStorage struct {
+	client    kubernetes.Interface
+	namespace string
+}
+
+// history returns the revisions of the given release, ordered from the
+// oldest to the newest.
+func (s *releaseStorage) history(ctx context.Context, name string) ([]*storedRelease, error) {
+	secret, err := s.client.CoreV1().Secrets(s.namespace).Get(
+		ctx, storagePrefix+name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, installer.ErrReleaseNotFound
+		}
+		return nil, fmt.Errorf("failed to get release %s: %w", name, err)
+	}
+	return decodeReleases(secret)
+}
+
+// last returns the newest revision of the given release.
+func (s *releaseStorage) last(ctx context.Context, name string) (*storedRelease, error) {
+	rels, err := s.history(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(rels) == 0 {
+		return nil, installer.ErrReleaseNotFound
+	}
+	return rels[len(rels)-1], nil
+}
+
+// append records the given revision of its release, superseding the
+// previous deployed revision when it is deployed, and forgetting the oldest
+// revisions beyond the maximum history.
+func (s *releaseStorage) append(ctx context.Context, rel *storedRelease) error {
+	rels, err := s.history(ctx, rel.Name)
+	if err != nil && !installer.IsReleaseNotFound(err) {
+		return err
+	}
+	create := installer.IsReleaseNotFound(err)
+
+	if rel.Status == installer.ReleaseStatusDeployed {
+		for _, prev := range rels {
+			if prev.Status == installer.ReleaseStatusDeployed {
+				prev.Status = installer.ReleaseStatusSuperseded
+			}
+		}
+	}
+	rels = append(rels, rel)
+	if len(rels) > maxHistory {
+		rels = rels[len(rels)-maxHistory:]
+	}
+
+	data, err := json.Marshal(rels)
+	if err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      storagePrefix + rel.Name,
+			Namespace: s.namespace,
+			Labels: map[string]string{
+				"name":  rel.Name,
+				"owner": "ocean-operator",
+			},
+		},
+		Type: storageType,
+		Data: map[string][]byte{storageKey: data},
+	}
+
+	secrets := s.client.CoreV1().Secrets(s.namespace)
+	if create {
+		_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+	} else {
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+// delete forgets all revisions of the given release.
+func (s *releaseStorage) delete(ctx context.Context, name string) error {
+	err := s.client.CoreV1().Secrets(s.namespace).Delete(
+		ctx, storagePrefix+name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// decodeReleases decodes the revisions recorded in the given Secret.
+func decodeReleases(secret *corev1.Secret) ([]*storedRelease, error) {
+	var rels []*storedRelease
+	if err := json.Unmarshal(secret.Data[storageKey], &rels); err != nil {
+		return nil, fmt.Errorf("invalid release secret %s: %w", secret.Name, err)
+	}
+	sort.Slice(rels, func(i, j int) bool { return rels[i].Revision < rels[j].Revision })
+	return rels, nil
+}
+
+// mergeInventories returns the objects of a followed by those of b that are
+// not in a.
+func mergeInventories(a, b []objectRef) []objectRef {
+	out := append([]objectRef(nil), a...)
+	seen := make(map[objectRef]bool, len(a))
+	for _, ref := range a {
+		seen[ref.key()] = true
+	}
+	for _, ref := range b {
+		if !seen[ref.key()] {
+			out = append(out, ref)
+		}
+	}
+	return out
+}
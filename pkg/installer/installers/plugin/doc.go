@@ -0,0 +1,60 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+// Package plugin implements installers that delegate to out-of-process
+// plugins, so that packaging formats can be added without forking the
+// operator.
+//
+// Plugins are discovered from the descriptor files (*.yaml, *.yml or *.json)
+// of a directory, each describing one plugin:
+//
+//	name: cdk8s                        # referenced by spec.plugin.name
+//	command: /opt/plugins/cdk8s        # an executable, run once per call, or
+//	args: ["--verbose"]
+//	endpoint: http://localhost:8090/   # a sidecar, which is sent POST requests
+//	timeout: 10m                       # of each call, defaults to 5m
+//
+// Exactly one of command and endpoint must be set. Each plugin is registered
+// as the installer named by InstallerName, used for OceanComponents of type
+// Plugin whose spec.plugin.name is the name of the plugin.
+//
+// # Protocol
+//
+// Each call of an installer method is a JSON request, written to the stdin of
+// the command or sent as the body of a POST request to the endpoint:
+//
+//	{
+//	  "apiVersion": "ocean.spot.io/plugin/v1",
+//	  "method": "Install",      // one of the methods below
+//	  "namespace": "spot-system", // namespace of the releases
+//	  "dryRun": false,
+//	  "name": "...",            // release name, for Get and History
+//	  "component": {...},       // OceanComponent, for the other methods
+//	  "release": {...},         // installed release, for IsUpgrade
+//	  "revision": 2             // target revision, for Rollback
+//	}
+//
+// The plugin answers with a JSON response, written to stdout or sent as the
+// body of the HTTP response, whose status should be 200 unless it reports an
+// error:
+//
+//	{
+//	  "release": {...},   // for Get, Install, Upgrade and Rollback
+//	  "releases": [...],  // for History, from the oldest to the newest
+//	  "upgrade": true,    // for IsUpgrade
+//	  "version": "1.2.3", // for ResolveVersion
+//	  "error": {"code": "NotFound", "message": "..."}
+//	}
+//
+// Releases have the fields of installer.Release. Methods are Get, Install,
+// Upgrade, Uninstall, IsUpgrade, Rollback, History and ResolveVersion; only
+// the first five must be implemented. Errors are reported with the codes
+// NotFound, when a release does not exist, NotImplemented, for unsupported
+// methods, VerificationFailed, or any other code. They are mapped onto
+// installer.ErrReleaseNotFound, installer.ErrNotImplemented and
+// installer.ErrVerificationFailed respectively. Commands exiting with a
+// non-zero status without writing a response fail with their stderr.
+//
+// Plugins that do not implement IsUpgrade are upgraded when the version of
+// their component changes, and plugins that do not implement ResolveVersion
+// install the version of their component as is.
+package plugin
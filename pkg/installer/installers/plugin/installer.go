@@ -0,0 +1,228 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/spotinst/ocean-operator/pkg/log"
+)
+
+// APIVersion is the version of the protocol spoken with plugins.
+const APIVersion = "ocean.spot.io/plugin/v1"
+
+// These are the methods of the protocol.
+const (
+	MethodGet            = "Get"
+	MethodInstall        = "Install"
+	MethodUpgrade        = "Upgrade"
+	MethodUninstall      = "Uninstall"
+	MethodIsUpgrade      = "IsUpgrade"
+	MethodRollback       = "Rollback"
+	MethodHistory        = "History"
+	MethodResolveVersion = "ResolveVersion"
+)
+
+// These are the error codes of the protocol mapped onto installer errors.
+const (
+	ErrorCodeNotFound           = "NotFound"
+	ErrorCodeNotImplemented     = "NotImplemented"
+	ErrorCodeVerificationFailed = "VerificationFailed"
+)
+
+type (
+	// Request is a call of an installer method sent to a plugin.
+	Request struct {
+		APIVersion string                       `json:"apiVersion"`
+		Method     string                       `json:"method"`
+		Namespace  string                       `json:"namespace"`
+		DryRun     bool                         `json:"dryRun,omitempty"`
+		Name       string                       `json:"name,omitempty"`
+		Component  *oceanv1beta1.OceanComponent `json:"component,omitempty"`
+		Release    *installer.Release           `json:"release,omitempty"`
+		Revision   int                          `json:"revision,omitempty"`
+	}
+
+	// Response is the answer of a plugin to a Request.
+	Response struct {
+		Release  *installer.Release   `json:"release,omitempty"`
+		Releases []*installer.Release `json:"releases,omitempty"`
+		Upgrade  bool                 `json:"upgrade,omitempty"`
+		Version  string               `json:"version,omitempty"`
+		Error    *Error               `json:"error,omitempty"`
+	}
+
+	// Error is an error reported by a plugin.
+	Error struct {
+		Code    string `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+// Unwrap returns the installer error the code of the error is mapped onto,
+// if any.
+func (e *Error) Unwrap() error {
+	switch e.Code {
+	case ErrorCodeNotFound:
+		return installer.ErrReleaseNotFound
+	case ErrorCodeNotImplemented:
+		return installer.ErrNotImplemented
+	case ErrorCodeVerificationFailed:
+		return installer.ErrVerificationFailed
+	default:
+		return nil
+	}
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Message != "":
+		return e.Message
+	case e.Unwrap() != nil:
+		return e.Unwrap().Error()
+	default:
+		return fmt.Sprintf("plugin error %q", e.Code)
+	}
+}
+
+// Installer installs components by delegating to a plugin.
+type Installer struct {
+	Plugin    *Plugin
+	Namespace string
+	DryRun    bool
+	Log       log.Logger
+}
+
+// NewInstaller returns a Installer delegating to the given plugin.
+func NewInstaller(plugin *Plugin, options *installer.InstallerOptions) *Installer {
+	return &Installer{
+		Plugin:    plugin,
+		Namespace: options.Namespace,
+		DryRun:    options.DryRun,
+		Log:       options.Log,
+	}
+}
+
+func (i *Installer) Get(ctx context.Context, name string) (*installer.Release, error) {
+	return i.callRelease(ctx, &Request{Method: MethodGet, Name: name})
+}
+
+func (i *Installer) Install(ctx context.Context, component *oceanv1beta1.OceanComponent) (*installer.Release, error) {
+	rel, err := i.callRelease(ctx, &Request{Method: MethodInstall, Component: component})
+	if err != nil {
+		return nil, fmt.Errorf("installation error: %w", err)
+	}
+	i.Log.Info("installed", "name", rel.Name, "plugin", i.Plugin.Name)
+	return rel, nil
+}
+
+func (i *Installer) Uninstall(ctx context.Context, component *oceanv1beta1.OceanComponent) error {
+	_, err := i.call(ctx, &Request{Method: MethodUninstall, Component: component})
+	if err != nil {
+		if installer.IsReleaseNotFound(err) {
+			i.Log.Info("release already uninstalled", "name", component.ReleaseName())
+			return nil
+		}
+		return fmt.Errorf("uninstallation error: %w", err)
+	}
+	i.Log.Info("uninstalled", "name", component.ReleaseName(), "plugin", i.Plugin.Name)
+	return nil
+}
+
+func (i *Installer) Upgrade(ctx context.Context, component *oceanv1beta1.OceanComponent) (*installer.Release, error) {
+	rel, err := i.callRelease(ctx, &Request{Method: MethodUpgrade, Component: component})
+	if err != nil {
+		return nil, fmt.Errorf("upgrade error: %w", err)
+	}
+	i.Log.Info("upgraded", "name", rel.Name, "plugin", i.Plugin.Name)
+	return rel, nil
+}
+
+func (i *Installer) Rollback(ctx context.Context, component *oceanv1beta1.OceanComponent, revision int) (*installer.Release, error) {
+	rel, err := i.callRelease(ctx, &Request{Method: MethodRollback, Component: component, Revision: revision})
+	if err != nil {
+		return nil, fmt.Errorf("rollback error: %w", err)
+	}
+	i.Log.Info("rolled back", "name", rel.Name, "revision", revision, "plugin", i.Plugin.Name)
+	return rel, nil
+}
+
+func (i *Installer) History(ctx context.Context, name string) ([]*installer.Release, error) {
+	res, err := i.call(ctx, &Request{Method: MethodHistory, Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return res.Releases, nil
+}
+
+// IsUpgrade asks the plugin whether the given release is an upgrade. When the
+// plugin does not implement it, only changes of version are upgrades.
+func (i *Installer) IsUpgrade(ctx context.Context, component *oceanv1beta1.OceanComponent, release *installer.Release) bool {
+	res, err := i.call(ctx, &Request{Method: MethodIsUpgrade, Component: component, Release: release})
+	if err != nil {
+		if installer.IsNotImplemented(err) {
+			return component.Spec.Version != release.Version
+		}
+		i.Log.Error(err, "failed to determine whether an upgrade is required")
+		return true // fail properly later
+	}
+	return res.Upgrade
+}
+
+// ResolveVersion asks the plugin to resolve the version of the given
+// component. When the plugin does not implement it, the version is returned
+// as is.
+func (i *Installer) ResolveVersion(ctx context.Context, component *oceanv1beta1.OceanComponent) (string, error) {
+	res, err := i.call(ctx, &Request{Method: MethodResolveVersion, Component: component})
+	if err != nil {
+		if installer.IsNotImplemented(err) {
+			return component.Spec.Version, nil
+		}
+		return "", err
+	}
+	return res.Version, nil
+}
+
+// callRelease calls the plugin with the given request, and returns the
+// release of its response.
+func (i *Installer) callRelease(ctx context.Context, req *Request) (*installer.Release, error) {
+	res, err := i.call(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if res.Release == nil {
+		return nil, fmt.Errorf("plugin %s: %s returned no release", i.Plugin.Name, req.Method)
+	}
+	return res.Release, nil
+}
+
+// call calls the plugin with the given request, and returns its response.
+// Errors reported by the plugin are wrapped *Error values.
+func (i *Installer) call(ctx context.Context, req *Request) (*Response, error) {
+	req.APIVersion = APIVersion
+	req.Namespace = i.Namespace
+	req.DryRun = i.DryRun
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	i.Log.V(5).Info("calling plugin", "plugin", i.Plugin.Name, "method", req.Method)
+	out, err := i.Plugin.call(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %s failed: %w", i.Plugin.Name, req.Method, err)
+	}
+
+	res := new(Response)
+	if err = json.Unmarshal(out, res); err != nil {
+		return nil, fmt.Errorf("plugin %s: %s returned an invalid response: %w", i.Plugin.Name, req.Method, err)
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("plugin %s: %s failed: %w", i.Plugin.Name, req.Method, res.Error)
+	}
+	return res, nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultTimeout is the time to wait for a plugin to answer a call, unless
+// its descriptor sets another.
+const DefaultTimeout = 5 * time.Minute
+
+// maxResponseSize is the maximum size, in bytes, of the response of a plugin.
+const maxResponseSize = 16 << 20
+
+// Plugin describes an out-of-process installer plugin.
+type Plugin struct {
+	// Name is the name of the plugin.
+	Name string `json:"name"`
+	// Command is the path of the executable of the plugin.
+	Command string `json:"command,omitempty"`
+	// Args are the arguments of Command.
+	Args []string `json:"args,omitempty"`
+	// Endpoint is the HTTP(S) URL of the sidecar of the plugin.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Timeout is the time to wait for the plugin to answer a call.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// InstallerName returns the name of the installer of the plugin with the
+// given name.
+func InstallerName(name string) string {
+	return oceanv1beta1.OceanComponentTypePlugin.String() + "/" + name
+}
+
+// Discover loads the plugins described by the descriptor files of the given
+// directory, and registers their installers. A missing directory holds no
+// plugins.
+func Discover(dir string) ([]*Plugin, error) {
+	plugins, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range plugins {
+		p := p
+		if err = installer.Register(InstallerName(p.Name),
+			func(options *installer.InstallerOptions) (installer.Installer, error) {
+				return NewInstaller(p, options), nil
+			}); err != nil {
+			return nil, err
+		}
+	}
+	return plugins, nil
+}
+
+// Load loads the plugins described by the descriptor files of the given
+// directory.
+func Load(dir string) ([]*Plugin, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read plugin directory: %w", err)
+	}
+
+	var plugins []*Plugin
+	names := make(map[string]string)
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+
+		file := filepath.Join(dir, entry.Name())
+		p, err := loadPlugin(file)
+		if err != nil {
+			return nil, err
+		}
+		if other, dup := names[p.Name]; dup {
+			return nil, fmt.Errorf("plugin %q is described by both %s and %s", p.Name, other, file)
+		}
+		names[p.Name] = file
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
+
+// loadPlugin loads the plugin described by the given descriptor file.
+func loadPlugin(file string) (*Plugin, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read plugin descriptor: %w", err)
+	}
+	p := new(Plugin)
+	if err = yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, fmt.Errorf("invalid plugin descriptor %s: %w", file, err)
+	}
+	if err = p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid plugin descriptor %s: %w", file, err)
+	}
+	if p.Timeout.Duration == 0 {
+		p.Timeout.Duration = DefaultTimeout
+	}
+	return p, nil
+}
+
+// validate validates the descriptor of the plugin.
+func (p *Plugin) validate() error {
+	if msgs := validation.IsDNS1123Label(p.Name); len(msgs) > 0 {
+		return fmt.Errorf("invalid name %q: %s", p.Name, strings.Join(msgs, ", "))
+	}
+	if (p.Command == "") == (p.Endpoint == "") {
+		return errors.New("exactly one of command and endpoint must be set")
+	}
+	if p.Command != "" && !filepath.IsAbs(p.Command) {
+		return fmt.Errorf("command %q must be an absolute path", p.Command)
+	}
+	if p.Endpoint != "" && !strings.HasPrefix(p.Endpoint, "http://") &&
+		!strings.HasPrefix(p.Endpoint, "https://") {
+		return fmt.Errorf("endpoint %q must be an HTTP(S) URL", p.Endpoint)
+	}
+	if p.Timeout.Duration < 0 {
+		return fmt.Errorf("timeout %s must be positive", p.Timeout.Duration)
+	}
+	return nil
+}
+
+// call sends the given request to the plugin and returns its response,
+// waiting for it until the timeout of the plugin, or until the given context
+// is done.
+func (p *Plugin) call(ctx context.Context, req []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout.Duration)
+	defer cancel()
+
+	var (
+		res []byte
+		err error
+	)
+	if p.Command != "" {
+		res, err = p.exec(ctx, req)
+	} else {
+		res, err = p.post(ctx, req)
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("call aborted: %w", ctx.Err())
+	}
+	return res, err
+}
+
+// exec runs the command of the plugin, writing the given request to its
+// stdin, and returns its stdout. When the command fails without writing to
+// stdout, its stderr is returned as an error.
+func (p *Plugin) exec(ctx context.Context, req []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Command, p.Args...)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil && stdout.Len() == 0 {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// post sends the given request to the endpoint of the plugin, and returns the
+// body of its response.
+func (p *Plugin) post(ctx context.Context, req []byte) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Endpoint, bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxResponseSize {
+		return nil, fmt.Errorf("response is larger than %d bytes", maxResponseSize)
+	}
+	// responses reporting an error may have any status
+	if res.StatusCode != http.StatusOK && !json.Valid(body) {
+		if msg := strings.TrimSpace(string(body)); msg != "" {
+			return nil, fmt.Errorf("%s: %s", res.Status, msg)
+		}
+		return nil, errors.New(res.Status)
+	}
+	return body, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/installer"
+	"github.com/spotinst/ocean-operator/pkg/log"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("whenValid", func(tt *testing.T) {
+		dir := tt.TempDir()
+		assert.NoError(tt, ioutil.WriteFile(filepath.Join(dir, "foo.yaml"),
+			[]byte("name: foo\ncommand: /bin/foo\n"), 0644))
+		assert.NoError(tt, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# plugins\n"), 0644))
+
+		plugins, err := Load(dir)
+		assert.NoError(tt, err)
+		if assert.Len(tt, plugins, 1) {
+			assert.Equal(tt, "foo", plugins[0].Name)
+			assert.Equal(tt, DefaultTimeout, plugins[0].Timeout.Duration)
+		}
+	})
+
+	t.Run("whenAmbiguous", func(tt *testing.T) {
+		dir := tt.TempDir()
+		assert.NoError(tt, ioutil.WriteFile(filepath.Join(dir, "foo.yaml"),
+			[]byte("name: foo\ncommand: /bin/foo\nendpoint: http://localhost:8090\n"), 0644))
+
+		_, err := Load(dir)
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenMissing", func(tt *testing.T) {
+		plugins, err := Load(filepath.Join(tt.TempDir(), "plugins"))
+		assert.NoError(tt, err)
+		assert.Empty(tt, plugins)
+	})
+}
+
+func TestInstaller(t *testing.T) {
+	comp := &oceanv1beta1.OceanComponent{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: oceanv1beta1.NamespaceSystem},
+		Spec: oceanv1beta1.OceanComponentSpec{
+			Type:    oceanv1beta1.OceanComponentTypePlugin,
+			Name:    "foo",
+			Version: "1.0.0",
+			Plugin:  &oceanv1beta1.PluginOptions{Name: "foo"},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		res := new(Response)
+		switch req.Method {
+		case MethodGet:
+			res.Error = &Error{Code: ErrorCodeNotFound, Message: "release foo not found"}
+		case MethodInstall:
+			res.Release = &installer.Release{Name: req.Component.Name, Version: req.Component.Spec.Version}
+		case MethodUpgrade:
+			time.Sleep(time.Second)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+			res.Error = &Error{Code: ErrorCodeNotImplemented}
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer srv.Close()
+
+	i := NewInstaller(&Plugin{
+		Name:     "foo",
+		Endpoint: srv.URL,
+		Timeout:  metav1.Duration{Duration: 100 * time.Millisecond},
+	}, &installer.InstallerOptions{Log: log.NullLogger})
+
+	t.Run("whenNotFound", func(tt *testing.T) {
+		_, err := i.Get(context.TODO(), "foo")
+		assert.True(tt, installer.IsReleaseNotFound(err))
+	})
+
+	t.Run("whenInstalled", func(tt *testing.T) {
+		rel, err := i.Install(context.TODO(), comp)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "1.0.0", rel.Version)
+	})
+
+	t.Run("whenTimedOut", func(tt *testing.T) {
+		_, err := i.Upgrade(context.TODO(), comp)
+		assert.True(tt, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("whenNotImplemented", func(tt *testing.T) {
+		_, err := i.History(context.TODO(), "foo")
+		assert.True(tt, installer.IsNotImplemented(err))
+		assert.False(tt, i.IsUpgrade(context.TODO(), comp, &installer.Release{Version: "1.0.0"}))
+		version, err := i.ResolveVersion(context.TODO(), comp)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "1.0.0", version)
+	})
+}
+
+func TestExec(t *testing.T) {
+	newInstaller := func(script string) *Installer {
+		return NewInstaller(&Plugin{
+			Name:    "foo",
+			Command: "/bin/sh",
+			Args:    []string{"-c", script},
+			Timeout: metav1.Duration{Duration: DefaultTimeout},
+		}, &installer.InstallerOptions{Log: log.NullLogger})
+	}
+
+	t.Run("whenAnswered", func(tt *testing.T) {
+		i := newInstaller(`cat >/dev/null; echo '{"release": {"name": "foo", "revision": 2}}'`)
+		rel, err := i.Get(context.TODO(), "foo")
+		assert.NoError(tt, err)
+		assert.Equal(tt, 2, rel.Revision)
+	})
+
+	t.Run("whenFailed", func(tt *testing.T) {
+		i := newInstaller(`echo "unknown command" >&2; exit 1`)
+		_, err := i.Get(context.TODO(), "foo")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unknown command")
+	})
+}
@@ -3,9 +3,11 @@
 package installer
 
 import (
+	"context"
 	"errors"
+	"time"
 
-	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
 )
 
 var (
@@ -13,21 +15,36 @@ var (
 	ErrNotImplemented = errors.New("installer: not implemented")
 	// ErrReleaseNotFound indicates that a component release is not found.
 	ErrReleaseNotFound = errors.New("installer: release not found")
+	// ErrInstallerNotFound indicates that no installer is registered by a name.
+	ErrInstallerNotFound = errors.New("installer: installer not found")
+	// ErrVerificationFailed indicates that the digest or provenance of a
+	// component archive could not be verified.
+	ErrVerificationFailed = errors.New("installer: verification failed")
 )
 
 type (
-	// Installer defines the interface of a component installer.
+	// Installer defines the interface of a component installer. Methods
+	// give up once the given context is done, such as when its deadline
+	// expires.
 	Installer interface {
 		// Get returns details of a component release by name.
-		Get(name oceanv1alpha1.OceanComponentName) (*Release, error)
+		Get(ctx context.Context, name string) (*Release, error)
 		// Install installs a component to a cluster.
-		Install(component *oceanv1alpha1.OceanComponent) (*Release, error)
+		Install(ctx context.Context, component *oceanv1beta1.OceanComponent) (*Release, error)
 		// Uninstall uninstalls a component from a cluster.
-		Uninstall(component *oceanv1alpha1.OceanComponent) error
+		Uninstall(ctx context.Context, component *oceanv1beta1.OceanComponent) error
 		// Upgrade upgrades a component to a cluster.
-		Upgrade(component *oceanv1alpha1.OceanComponent) (*Release, error)
+		Upgrade(ctx context.Context, component *oceanv1beta1.OceanComponent) (*Release, error)
+		// Rollback rolls a component release back to the given revision.
+		Rollback(ctx context.Context, component *oceanv1beta1.OceanComponent, revision int) (*Release, error)
+		// History returns the revisions of a component release by name,
+		// ordered from the oldest to the newest.
+		History(ctx context.Context, name string) ([]*Release, error)
 		// IsUpgrade determines whether a component release is an upgrade.
-		IsUpgrade(component *oceanv1alpha1.OceanComponent, release *Release) bool
+		IsUpgrade(ctx context.Context, component *oceanv1beta1.OceanComponent, release *Release) bool
+		// ResolveVersion resolves the version constraint of a component to
+		// the latest version satisfying it. Exact versions are returned as is.
+		ResolveVersion(ctx context.Context, component *oceanv1beta1.OceanComponent) (string, error)
 	}
 
 	// Release describes a deployment of a component. For Helm-based components,
@@ -39,6 +56,8 @@ type (
 		Version string `json:"version,omitempty"`
 		// AppVersion is the version of the application enclosed inside of this release.
 		AppVersion string `json:"appVersion,omitempty"`
+		// Revision is the revision number of the release.
+		Revision int `json:"revision,omitempty"`
 		// Status is the current state of the release.
 		Status ReleaseStatus `json:"status,omitempty"`
 		// Description is human-friendly "log entry" about this release.
@@ -47,6 +66,9 @@ type (
 		Values map[string]interface{} `json:"values,omitempty"`
 		// Manifest is the string representation of the rendered template.
 		Manifest string `json:"manifest,omitempty"`
+		// LastDeployed is the time the release was last deployed, or the time
+		// its pending operation started. Zero if unknown.
+		LastDeployed time.Time `json:"lastDeployed,omitempty"`
 	}
 )
 
@@ -63,6 +85,9 @@ const (
 	ReleaseStatusUninstalled ReleaseStatus = "Uninstalled"
 	// ReleaseStatusFailed indicates that the release was not successfully deployed.
 	ReleaseStatusFailed ReleaseStatus = "Failed"
+	// ReleaseStatusSuperseded indicates that the release was deployed, and
+	// has since been replaced by a newer revision.
+	ReleaseStatusSuperseded ReleaseStatus = "Superseded"
 	// ReleaseStatusProgressing indicates that a release is in progress.
 	ReleaseStatusProgressing ReleaseStatus = "Progressing"
 )
@@ -74,7 +99,17 @@ func IsNotImplemented(err error) bool {
 	return errors.Is(err, ErrNotImplemented)
 }
 
+// IsInstallerNotFound returns true if the specified error is ErrInstallerNotFound.
+func IsInstallerNotFound(err error) bool {
+	return errors.Is(err, ErrInstallerNotFound)
+}
+
 // IsReleaseNotFound returns true if the specified error is ErrReleaseNotFound.
 func IsReleaseNotFound(err error) bool {
 	return errors.Is(err, ErrReleaseNotFound)
 }
+
+// IsVerificationFailed returns true if the specified error is ErrVerificationFailed.
+func IsVerificationFailed(err error) bool {
+	return errors.Is(err, ErrVerificationFailed)
+}
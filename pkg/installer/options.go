@@ -3,6 +3,7 @@
 package installer
 
 import (
+	"github.com/spotinst/ocean-operator/pkg/installer/cache"
 	"github.com/spotinst/ocean-operator/pkg/log"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
@@ -13,6 +14,7 @@ type InstallerOptions struct {
 	Namespace    string
 	ClientGetter genericclioptions.RESTClientGetter
 	DryRun       bool
+	Cache        *cache.Cache
 	Log          log.Logger
 }
 
@@ -61,6 +63,13 @@ func WithDryRun(dryRun bool) InstallerOption {
 	})
 }
 
+// WithCache sets the given cache of downloaded artifacts.
+func WithCache(cache *cache.Cache) InstallerOption {
+	return InstallerOptionFunc(func(options *InstallerOptions) {
+		options.Cache = cache
+	})
+}
+
 // WithLogger sets the given logger.
 func WithLogger(log log.Logger) InstallerOption {
 	return InstallerOptionFunc(func(options *InstallerOptions) {
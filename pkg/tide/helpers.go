@@ -6,7 +6,7 @@ import (
 	"context"
 	"fmt"
 
-	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
 	"github.com/spotinst/ocean-operator/pkg/config"
 	"github.com/spotinst/ocean-operator/pkg/credentials"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,7 +37,7 @@ func LoadConfig(ctx context.Context, client client.Client) (*config.Value, error
 		&config.ConfigMapProvider{
 			Client:    client,
 			Name:      OceanOperatorConfigMap,
-			Namespace: oceanv1alpha1.NamespaceSystem,
+			Namespace: oceanv1beta1.NamespaceSystem,
 		},
 		&config.ConfigMapProvider{
 			Client:    client,
@@ -70,7 +70,7 @@ func LoadCredentials(ctx context.Context, client client.Client) (*credentials.Va
 		&credentials.SecretProvider{
 			Client:    client,
 			Name:      OceanOperatorSecret,
-			Namespace: oceanv1alpha1.NamespaceSystem,
+			Namespace: oceanv1beta1.NamespaceSystem,
 		},
 		&credentials.SecretProvider{
 			Client:    client,
@@ -6,6 +6,7 @@ import (
 	"context"
 
 	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -19,6 +20,7 @@ func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 	_ = apiextensionsv1.AddToScheme(scheme)
 	_ = oceanv1alpha1.AddToScheme(scheme)
+	_ = oceanv1beta1.AddToScheme(scheme)
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -32,10 +34,16 @@ type (
 		ApplyEnvironment(
 			ctx context.Context,
 			options ...ApplyOption) error
+		// ApplyOceanEnvironment applies an environment resource. An existing
+		// environment is left untouched.
+		ApplyOceanEnvironment(
+			ctx context.Context,
+			environment *oceanv1beta1.OceanEnvironment,
+			options ...ApplyOption) error
 		// ApplyComponents applies component resources.
 		ApplyComponents(
 			ctx context.Context,
-			components []*oceanv1alpha1.OceanComponent,
+			components []*oceanv1beta1.OceanComponent,
 			options ...ApplyOption) error
 		// ApplyCRDs applies CRD resources.
 		ApplyCRDs(
@@ -56,10 +64,15 @@ type (
 		DeleteEnvironment(
 			ctx context.Context,
 			options ...DeleteOption) error
+		// DeleteOceanEnvironments deletes environment resources.
+		DeleteOceanEnvironments(
+			ctx context.Context,
+			environments []oceanv1beta1.OceanEnvironment,
+			options ...DeleteOption) error
 		// DeleteComponents deletes component resources.
 		DeleteComponents(
 			ctx context.Context,
-			components []oceanv1alpha1.OceanComponent,
+			components []oceanv1beta1.OceanComponent,
 			options ...DeleteOption) error
 		// DeleteCRDs deletes CRD resources.
 		DeleteCRDs(
@@ -8,10 +8,11 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
-	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
 	_ "github.com/spotinst/ocean-operator/pkg/installer/installers"
 	"github.com/spotinst/ocean-operator/pkg/log"
 	tiderbac "github.com/spotinst/ocean-operator/pkg/tide/rbac"
@@ -24,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -32,6 +34,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 const (
@@ -110,11 +113,8 @@ func (m *manager) ApplyEnvironment(ctx context.Context, options ...ApplyOption)
 		return err
 	}
 
-	oceanComponents, err := m.loadComponents(opts)
-	if err != nil {
-		return err
-	}
-	if err = m.ApplyComponents(ctx, oceanComponents, options...); err != nil {
+	oceanEnvironment := m.loadEnvironment(opts)
+	if err = m.ApplyOceanEnvironment(ctx, oceanEnvironment, options...); err != nil {
 		return err
 	}
 
@@ -247,6 +247,9 @@ func (m *manager) applyCRD(ctx context.Context,
 				return err
 			}
 			crd.SetResourceVersion(existing.GetResourceVersion())
+			preserveConversion(crd, existing)
+			// the status is served by its own subresource
+			crd.Status = existing.Status
 			return m.clientRuntime.Patch(ctx, crd, client.MergeFrom(existing))
 		}); err != nil {
 			return err
@@ -255,7 +258,7 @@ func (m *manager) applyCRD(ctx context.Context,
 
 	// wait for the crd to be available
 	if err = m.waitForCRD(ctx, crd); err != nil {
-		if deleteErr := m.clientRuntime.Delete(ctx, crd, nil); deleteErr != nil {
+		if deleteErr := m.clientRuntime.Delete(ctx, crd); deleteErr != nil {
 			return fmt.Errorf("unable to delete crd %s: %w "+
 				"(deleting crd due: %v)", crd.Name, deleteErr, err)
 		}
@@ -265,8 +268,66 @@ func (m *manager) applyCRD(ctx context.Context,
 	return nil
 }
 
+// preserveConversion carries the conversion webhook of an existing crd over to
+// the crd being applied, along with the annotations and labels the embedded crd
+// does not set (e.g. cert-manager's CA injection), so that installations that
+// serve the conversion webhook keep converting stored objects.
+func preserveConversion(crd, existing *apiextensionsv1.CustomResourceDefinition) {
+	for k, v := range existing.Annotations {
+		if _, ok := crd.Annotations[k]; !ok {
+			metav1.SetMetaDataAnnotation(&crd.ObjectMeta, k, v)
+		}
+	}
+	for k, v := range existing.Labels {
+		if _, ok := crd.Labels[k]; !ok {
+			if crd.Labels == nil {
+				crd.Labels = make(map[string]string)
+			}
+			crd.Labels[k] = v
+		}
+	}
+
+	current := existing.Spec.Conversion
+	if current == nil || current.Strategy != apiextensionsv1.WebhookConverter {
+		return
+	}
+	desired := crd.Spec.Conversion
+	switch {
+	case desired == nil || desired.Strategy == apiextensionsv1.NoneConverter:
+		crd.Spec.Conversion = current.DeepCopy()
+	case desired.Webhook != nil && desired.Webhook.ClientConfig != nil &&
+		len(desired.Webhook.ClientConfig.CABundle) == 0 &&
+		current.Webhook != nil && current.Webhook.ClientConfig != nil:
+		desired.Webhook.ClientConfig.CABundle = current.Webhook.ClientConfig.CABundle
+	}
+}
+
+func (m *manager) ApplyOceanEnvironment(ctx context.Context,
+	env *oceanv1beta1.OceanEnvironment, options ...ApplyOption) error {
+	m.log.Info("applying ocean environment")
+
+	// the environment is created once, and is managed by its owner from then
+	// on, so that changes made to it are never overwritten
+	existing := new(oceanv1beta1.OceanEnvironment)
+	objKey := client.ObjectKeyFromObject(env)
+	err := m.clientRuntime.Get(ctx, objKey, existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		m.log.V(1).Info("creating ocean environment", "name", objKey.Name)
+		if err := m.clientRuntime.Create(ctx, env); err != nil {
+			return fmt.Errorf("unable to create ocean environment %q: %w", objKey.Name, err)
+		}
+	case err != nil:
+		return fmt.Errorf("unable to get ocean environment %q to check if it exists: %w", objKey.Name, err)
+	default:
+		m.log.V(1).Info("skipping existing ocean environment", "name", objKey.Name)
+	}
+
+	return nil
+}
+
 func (m *manager) ApplyComponents(ctx context.Context,
-	components []*oceanv1alpha1.OceanComponent, options ...ApplyOption) error {
+	components []*oceanv1beta1.OceanComponent, options ...ApplyOption) error {
 	opts := mutateApplyOptions(options...)
 
 	m.log.Info("applying ocean components")
@@ -280,8 +341,8 @@ func (m *manager) ApplyComponents(ctx context.Context,
 }
 
 func (m *manager) applyComponent(ctx context.Context,
-	component *oceanv1alpha1.OceanComponent, options *ApplyOptions) error {
-	if component.Spec.State == oceanv1alpha1.OceanComponentStateAbsent {
+	component *oceanv1beta1.OceanComponent, options *ApplyOptions) error {
+	if component.Spec.State == oceanv1beta1.OceanComponentStateAbsent {
 		m.log.V(1).Info("skipping ocean component",
 			"name", component.Name, "state", component.Spec.State)
 		return nil
@@ -330,9 +391,24 @@ func (m *manager) applyComponent(ctx context.Context,
 // region Deleters
 
 func (m *manager) DeleteEnvironment(ctx context.Context, options ...DeleteOption) error {
+	m.log.Info("deleting ocean environments")
+
+	environmentList := new(oceanv1beta1.OceanEnvironmentList)
+	if err := m.clientRuntime.List(ctx, environmentList); err != nil {
+		environmentGone, ok := err.(*apimeta.NoKindMatchError)
+		if ok {
+			m.log.V(1).Info("ocean environments are not present", "message", environmentGone.Error())
+		} else {
+			return err
+		}
+	}
+	if err := m.DeleteOceanEnvironments(ctx, environmentList.Items, options...); err != nil {
+		return err
+	}
+
 	m.log.Info("deleting ocean components")
 
-	componentList := new(oceanv1alpha1.OceanComponentList)
+	componentList := new(oceanv1beta1.OceanComponentList)
 	if err := m.clientRuntime.List(ctx, componentList); err != nil {
 		componentGone, ok := err.(*apimeta.NoKindMatchError)
 		if ok {
@@ -345,19 +421,23 @@ func (m *manager) DeleteEnvironment(ctx context.Context, options ...DeleteOption
 		return err
 	}
 
-	crdList := new(apiextensionsv1.CustomResourceDefinitionList)
-	crdFieldSet := client.MatchingFields{
-		"metadata.name": fmt.Sprintf("oceancomponents.%s", oceanv1alpha1.GroupVersion.Group),
-	}
-	if err := m.clientRuntime.List(ctx, crdList, crdFieldSet); err != nil {
-		crdGone, ok := err.(*apimeta.NoKindMatchError)
-		if ok {
-			m.log.Info("ocean crds are not present", "message", crdGone.Error())
-		} else {
-			return err
+	var oceanCRDs []apiextensionsv1.CustomResourceDefinition
+	for _, resource := range []string{"oceancomponents", "oceanenvironments"} {
+		crdList := new(apiextensionsv1.CustomResourceDefinitionList)
+		crdFieldSet := client.MatchingFields{
+			"metadata.name": fmt.Sprintf("%s.%s", resource, oceanv1beta1.GroupVersion.Group),
 		}
+		if err := m.clientRuntime.List(ctx, crdList, crdFieldSet); err != nil {
+			crdGone, ok := err.(*apimeta.NoKindMatchError)
+			if ok {
+				m.log.Info("ocean crds are not present", "message", crdGone.Error())
+			} else {
+				return err
+			}
+		}
+		oceanCRDs = append(oceanCRDs, crdList.Items...)
 	}
-	if err := m.DeleteCRDs(ctx, crdList.Items, options...); err != nil {
+	if err := m.DeleteCRDs(ctx, oceanCRDs, options...); err != nil {
 		return err
 	}
 
@@ -463,8 +543,23 @@ func (m *manager) deleteCRD(ctx context.Context,
 	return nil
 }
 
+func (m *manager) DeleteOceanEnvironments(ctx context.Context,
+	environments []oceanv1beta1.OceanEnvironment, options ...DeleteOption) error {
+	m.log.Info("deleting ocean environments")
+	for _, env := range environments {
+		m.log.V(1).Info("deleting ocean environment", "name", env.Name)
+		// owned components are deleted along with the environment, and then
+		// waited for by DeleteComponents
+		if err := m.clientRuntime.Delete(ctx, &env); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("could not delete ocean environment: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (m *manager) DeleteComponents(ctx context.Context,
-	components []oceanv1alpha1.OceanComponent, options ...DeleteOption) error {
+	components []oceanv1beta1.OceanComponent, options ...DeleteOption) error {
 	opts := mutateDeleteOptions(options...)
 
 	m.log.Info("deleting ocean components")
@@ -477,7 +572,7 @@ func (m *manager) DeleteComponents(ctx context.Context,
 	m.log.Info("waiting for ocean components to be deleted")
 	return wait.Poll(5*time.Second, 300*time.Second, func() (bool, error) {
 		for _, component := range components {
-			obj := new(oceanv1alpha1.OceanComponent)
+			obj := new(oceanv1beta1.OceanComponent)
 			objKey := types.NamespacedName{
 				Namespace: component.Namespace,
 				Name:      component.Name,
@@ -494,7 +589,7 @@ func (m *manager) DeleteComponents(ctx context.Context,
 }
 
 func (m *manager) deleteComponent(ctx context.Context,
-	component *oceanv1alpha1.OceanComponent, options *DeleteOptions) error {
+	component *oceanv1beta1.OceanComponent, options *DeleteOptions) error {
 	m.log.V(1).Info("deleting ocean component", "name", component.Name)
 
 	if err := m.clientRuntime.Delete(ctx, component); err != nil && !apierrors.IsNotFound(err) {
@@ -557,9 +652,34 @@ func (m *manager) loadCRD(name string) (*apiextensionsv1.CustomResourceDefinitio
 	return crd, nil
 }
 
-func (m *manager) loadComponents(options *ApplyOptions) ([]*oceanv1alpha1.OceanComponent, error) {
-	m.log.V(1).Info("loading ocean components")
+func (m *manager) loadEnvironment(options *ApplyOptions) *oceanv1beta1.OceanEnvironment {
+	m.log.V(1).Info("loading ocean environment")
 
+	env := &oceanv1beta1.OceanEnvironment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: oceanv1beta1.DefaultOceanEnvironmentName,
+		},
+		Spec: oceanv1beta1.OceanEnvironmentSpec{
+			Namespace: options.Namespace,
+		},
+	}
+	for name := range options.ComponentsFilter {
+		env.Spec.Components = append(env.Spec.Components,
+			oceanv1beta1.OceanEnvironmentComponent{Name: name})
+	}
+	sort.Slice(env.Spec.Components, func(i, j int) bool {
+		return env.Spec.Components[i].Name < env.Spec.Components[j].Name
+	})
+
+	m.log.V(4).Info("loaded environment", "environment", env)
+	return env
+}
+
+// LoadComponents loads the built-in component manifests. Components are
+// returned as defined by the manifests, and must be filtered by the caller.
+// The dependencies and health checks of the component catalog are added to
+// the components that do not declare them.
+func LoadComponents() ([]*oceanv1beta1.OceanComponent, error) {
 	dd, err := components.ReadDir(componentsDirName)
 	if err != nil {
 		return nil, fmt.Errorf("components in %s cannot be listed: %w", componentsDirName, err)
@@ -573,18 +693,19 @@ func (m *manager) loadComponents(options *ApplyOptions) ([]*oceanv1alpha1.OceanC
 		return nil, fmt.Errorf("no component manifests found")
 	}
 
-	oceanComponents := make([]*oceanv1alpha1.OceanComponent, 0, len(manifests))
+	oceanComponents := make([]*oceanv1beta1.OceanComponent, 0, len(manifests))
 	for _, manifest := range manifests {
-		comp, err := m.loadComponent(manifest)
+		comp, err := loadComponent(manifest)
 		if err != nil {
 			return nil, err
 		}
-		if _, present := options.ComponentsFilter[comp.Spec.Name]; present { // component name
-			comp.Spec.State = oceanv1alpha1.OceanComponentStatePresent
-		} else if _, present = options.ComponentsFilter[oceanv1alpha1.OceanComponentName(comp.Name)]; present { // resource name
-			comp.Spec.State = oceanv1alpha1.OceanComponentStatePresent
-		} else {
-			comp.Spec.State = oceanv1alpha1.OceanComponentStateAbsent
+		if entry, ok := oceanv1beta1.LookupCatalog(oceanv1beta1.OceanComponentName(comp.Name)); ok {
+			if comp.Spec.DependsOn == nil {
+				comp.Spec.DependsOn = entry.Spec.DependsOn
+			}
+			if comp.Spec.HealthChecks == nil {
+				comp.Spec.HealthChecks = entry.Spec.HealthChecks
+			}
 		}
 		oceanComponents = append(oceanComponents, comp)
 	}
@@ -592,24 +713,32 @@ func (m *manager) loadComponents(options *ApplyOptions) ([]*oceanv1alpha1.OceanC
 	return oceanComponents, nil
 }
 
-func (m *manager) loadComponent(name string) (*oceanv1alpha1.OceanComponent, error) {
-	comp := new(oceanv1alpha1.OceanComponent)
+func loadComponent(name string) (*oceanv1beta1.OceanComponent, error) {
 	data, err := components.ReadFile(name)
 	if err != nil {
 		return nil, fmt.Errorf("cannot read file %s: %w", name, err)
 	}
 
-	serializer := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
-	_, _, err = serializer.Decode(data, &schema.GroupVersionKind{
-		Group:   "ocean.spot.io",
-		Version: "v1alpha1",
-		Kind:    "OceanComponent",
-	}, comp)
+	// manifests may be written in any served version of the api, so decode
+	// them using the version they declare and convert them to the hub version
+	obj, _, err := serializer.NewCodecFactory(scheme).UniversalDeserializer().Decode(data, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("cannot load component %s: %w", name, err)
 	}
 
-	m.log.V(4).Info("loaded component", "component", comp)
+	comp := new(oceanv1beta1.OceanComponent)
+	switch o := obj.(type) {
+	case *oceanv1beta1.OceanComponent:
+		comp = o
+	case conversion.Convertible:
+		if err = o.ConvertTo(comp); err != nil {
+			return nil, fmt.Errorf("cannot convert component %s: %w", name, err)
+		}
+	default:
+		return nil, fmt.Errorf("cannot load component %s: unexpected kind %s",
+			name, obj.GetObjectKind().GroupVersionKind().Kind)
+	}
+
 	return comp, nil
 }
 
@@ -0,0 +1,100 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package tide
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/spotinst/ocean-operator/pkg/log"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyCRD(t *testing.T) {
+	m := &manager{log: log.NullLogger}
+	options := &ApplyOptions{Namespace: "spot-system"}
+
+	newExisting := func(embedded *apiextensionsv1.CustomResourceDefinition) *apiextensionsv1.CustomResourceDefinition {
+		existing := embedded.DeepCopy()
+		existing.Namespace = options.Namespace // as set by applyCRD
+		existing.Annotations = map[string]string{
+			"cert-manager.io/inject-ca-from": "spot-system/ocean-operator-serving-cert",
+		}
+		existing.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+			Strategy: apiextensionsv1.WebhookConverter,
+			Webhook: &apiextensionsv1.WebhookConversion{
+				ClientConfig: &apiextensionsv1.WebhookClientConfig{
+					Service: &apiextensionsv1.ServiceReference{
+						Namespace: "spot-system",
+						Name:      "ocean-operator-webhook-service",
+						Path:      func(s string) *string { return &s }("/convert"),
+						Port:      func(p int32) *int32 { return &p }(443),
+					},
+					CABundle: []byte("ca"),
+				},
+				ConversionReviewVersions: []string{"v1"},
+			},
+		}
+		existing.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+			{
+				Type:   apiextensionsv1.Established,
+				Status: apiextensionsv1.ConditionTrue,
+			},
+		}
+		return existing
+	}
+
+	t.Run("whenConversionWebhookConfigured", func(tt *testing.T) {
+		embedded, err := m.loadCRD(path.Join(crdsDirName, "ocean.spot.io_oceancomponents.yaml"))
+		assert.NoError(tt, err)
+		existing := newExisting(embedded)
+
+		m.clientRuntime = fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()
+		assert.NoError(tt, m.applyCRD(context.Background(), embedded, options))
+
+		applied := new(apiextensionsv1.CustomResourceDefinition)
+		assert.NoError(tt, m.clientRuntime.Get(context.Background(), client.ObjectKeyFromObject(existing), applied))
+		assert.Equal(tt, existing.Spec.Conversion, applied.Spec.Conversion)
+		assert.Equal(tt, existing.Annotations["cert-manager.io/inject-ca-from"],
+			applied.Annotations["cert-manager.io/inject-ca-from"])
+	})
+
+	t.Run("whenEmbeddedWebhookHasNoCABundle", func(tt *testing.T) {
+		embedded, err := m.loadCRD(path.Join(crdsDirName, "ocean.spot.io_oceancomponents.yaml"))
+		assert.NoError(tt, err)
+		existing := newExisting(embedded)
+		embedded.Spec.Conversion = existing.Spec.Conversion.DeepCopy()
+		embedded.Spec.Conversion.Webhook.ClientConfig.CABundle = nil
+
+		preserveConversion(embedded, existing)
+		assert.Equal(tt, []byte("ca"), embedded.Spec.Conversion.Webhook.ClientConfig.CABundle)
+	})
+}
+
+func TestLoadComponents(t *testing.T) {
+	comps, err := LoadComponents()
+	assert.NoError(t, err)
+	assert.Len(t, comps, len(oceanv1beta1.Catalog()))
+
+	// the manifests and the catalog must describe the same components
+	for _, comp := range comps {
+		entry, ok := oceanv1beta1.LookupCatalog(oceanv1beta1.OceanComponentName(comp.Name))
+		if assert.True(t, ok, comp.Name) {
+			assert.Equal(t, entry.Name.String(), comp.Name)
+			assert.Equal(t, entry.Spec.Type, comp.Spec.Type)
+			assert.Equal(t, entry.Spec.Name, comp.Spec.Name)
+			assert.Equal(t, entry.Spec.URL, comp.Spec.URL)
+			assert.Equal(t, entry.Spec.Version, comp.Spec.Version)
+			assert.Equal(t, entry.Spec.DependsOn, comp.Spec.DependsOn)
+			assert.Equal(t, entry.Spec.HealthChecks, comp.Spec.HealthChecks)
+			if entry.Spec.Values != nil {
+				assert.JSONEq(t, string(entry.Spec.Values.Raw), string(comp.Spec.Values.Raw))
+			}
+		}
+	}
+}
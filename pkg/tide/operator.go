@@ -8,26 +8,27 @@ import (
 	"fmt"
 	"time"
 
-	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
 	"github.com/spotinst/ocean-operator/pkg/installer"
 	"github.com/spotinst/ocean-operator/pkg/log"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilwait "k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes"
 )
 
-// NewOperatorOceanComponent returns an oceanv1alpha1.OceanComponent
+// NewOperatorOceanComponent returns an oceanv1beta1.OceanComponent
 // representing the Ocean Operator.
-func NewOperatorOceanComponent(options ...ChartOption) *oceanv1alpha1.OceanComponent {
-	comp := &oceanv1alpha1.OceanComponent{
+func NewOperatorOceanComponent(options ...ChartOption) *oceanv1beta1.OceanComponent {
+	comp := &oceanv1beta1.OceanComponent{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: oceanv1alpha1.NamespaceSystem,
+			Namespace: oceanv1beta1.NamespaceSystem,
 			Name:      OceanOperatorChart,
 		},
-		Spec: oceanv1alpha1.OceanComponentSpec{
-			Type:    oceanv1alpha1.OceanComponentTypeHelm,
-			State:   oceanv1alpha1.OceanComponentStatePresent,
+		Spec: oceanv1beta1.OceanComponentSpec{
+			Type:    oceanv1beta1.OceanComponentTypeHelm,
+			State:   oceanv1beta1.OceanComponentStatePresent,
 			Name:    OceanOperatorChart,
 			URL:     OceanOperatorRepository,
 			Version: OceanOperatorVersion,
@@ -36,10 +37,12 @@ func NewOperatorOceanComponent(options ...ChartOption) *oceanv1alpha1.OceanCompo
 
 	opts := mutateChartOptions(options...)
 	comp.Namespace = opts.Namespace
-	comp.Spec.Name = oceanv1alpha1.OceanComponentName(opts.Name)
+	comp.Spec.Name = oceanv1beta1.OceanComponentName(opts.Name)
 	comp.Spec.URL = opts.URL
 	comp.Spec.Version = opts.Version
-	comp.Spec.Values = opts.Values
+	if opts.Values != "" {
+		comp.Spec.Values = &apiextensionsv1.JSON{Raw: []byte(opts.Values)}
+	}
 
 	return comp
 }
@@ -47,7 +50,7 @@ func NewOperatorOceanComponent(options ...ChartOption) *oceanv1alpha1.OceanCompo
 // InstallOperator installs the Ocean Operator.
 func InstallOperator(
 	ctx context.Context,
-	operator *oceanv1alpha1.OceanComponent,
+	operator *oceanv1beta1.OceanComponent,
 	clientGetter genericclioptions.RESTClientGetter,
 	wait, dryRun bool,
 	timeout time.Duration,
@@ -66,19 +69,19 @@ func InstallOperator(
 			return err
 		}
 
-		existing, err := i.Get(operator.Spec.Name)
+		existing, err := i.Get(ctx, operator.ReleaseName())
 		if err != nil && !installer.IsReleaseNotFound(err) {
 			log.Error(err, "error checking ocean operator release")
 			return err
 		}
 
 		var release *installer.Release
-		if existing != nil && i.IsUpgrade(operator, existing) {
+		if existing != nil && i.IsUpgrade(ctx, operator, existing) {
 			log.Info("upgrading ocean operator")
-			release, err = i.Upgrade(operator)
+			release, err = i.Upgrade(ctx, operator)
 		} else {
 			log.Info("installing ocean operator")
-			release, err = i.Install(operator)
+			release, err = i.Install(ctx, operator)
 		}
 		if err != nil {
 			return fmt.Errorf("cannot release ocean operator: %w", err)
@@ -125,7 +128,7 @@ func InstallOperator(
 // UninstallOperator uninstalls the Ocean Operator.
 func UninstallOperator(
 	ctx context.Context,
-	operator *oceanv1alpha1.OceanComponent,
+	operator *oceanv1beta1.OceanComponent,
 	clientGetter genericclioptions.RESTClientGetter,
 	wait, dryRun bool,
 	timeout time.Duration,
@@ -144,7 +147,7 @@ func UninstallOperator(
 			return err
 		}
 
-		existing, err := i.Get(operator.Spec.Name)
+		existing, err := i.Get(ctx, operator.ReleaseName())
 		if err != nil && !installer.IsReleaseNotFound(err) {
 			log.Error(err, "error checking ocean operator release")
 			return err
@@ -152,7 +155,7 @@ func UninstallOperator(
 
 		if existing != nil {
 			log.Info("uninstalling ocean operator")
-			if err = i.Uninstall(operator); err != nil {
+			if err = i.Uninstall(ctx, operator); err != nil {
 				return fmt.Errorf("cannot uninstall ocean operator: %w", err)
 			}
 		}
@@ -3,7 +3,7 @@
 package tide
 
 import (
-	oceanv1alpha1 "github.com/spotinst/ocean-operator/api/v1alpha1"
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
 )
 
 // region Options
@@ -11,7 +11,7 @@ import (
 // ApplyOptions contains apply options.
 type ApplyOptions struct {
 	Namespace        string
-	ComponentsFilter map[oceanv1alpha1.OceanComponentName]struct{}
+	ComponentsFilter map[oceanv1beta1.OceanComponentName]struct{}
 }
 
 // DeleteOptions contains delete options.
@@ -103,7 +103,7 @@ var (
 )
 
 // WithComponentsFilter sets the given ComponentsFilter list.
-func WithComponentsFilter(components ...oceanv1alpha1.OceanComponentName) ComponentsFilter {
+func WithComponentsFilter(components ...oceanv1beta1.OceanComponentName) ComponentsFilter {
 	return ComponentsFilter{
 		components: components,
 	}
@@ -111,12 +111,12 @@ func WithComponentsFilter(components ...oceanv1alpha1.OceanComponentName) Compon
 
 // ComponentsFilter filters components to be applied or deleted.
 type ComponentsFilter struct {
-	components []oceanv1alpha1.OceanComponentName
+	components []oceanv1beta1.OceanComponentName
 }
 
 // MutateApplyOptions implements the ApplyOption interface.
 func (w ComponentsFilter) MutateApplyOptions(options *ApplyOptions) {
-	options.ComponentsFilter = make(map[oceanv1alpha1.OceanComponentName]struct{})
+	options.ComponentsFilter = make(map[oceanv1beta1.OceanComponentName]struct{})
 	for _, component := range w.components {
 		options.ComponentsFilter[component] = struct{}{}
 	}
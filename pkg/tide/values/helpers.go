@@ -9,9 +9,32 @@ import (
 	"io"
 
 	"gopkg.in/yaml.v3"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
 )
 
+// FromJSON returns the given structured values as a string. Since JSON is a
+// subset of YAML, the result can be passed to any function accepting values.
+func FromJSON(values *apiextensionsv1.JSON) string {
+	if values == nil {
+		return ""
+	}
+	return string(values.Raw)
+}
+
+// ToJSON converts the given YAML or JSON values into structured values. An
+// empty string results in nil values.
+func ToJSON(values string) (*apiextensionsv1.JSON, error) {
+	if len(bytes.TrimSpace([]byte(values))) == 0 {
+		return nil, nil
+	}
+	raw, err := yamlutil.ToJSON([]byte(values))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert values to json: %w", err)
+	}
+	return &apiextensionsv1.JSON{Raw: raw}, nil
+}
+
 func Merge(values ...string) (string, error) {
 	list := make([]map[string]interface{}, 0, len(values))
 	for _, value := range values {
@@ -0,0 +1,112 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package values
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FromReferences resolves the given references to ConfigMaps and Secrets in
+// namespace and returns their values in the order they are listed, ready to
+// be passed to Merge.
+func FromReferences(ctx context.Context, client client.Client, namespace string,
+	refs []oceanv1beta1.ValuesReference) ([]string, error) {
+	out := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		value, found, err := fromReference(ctx, client, namespace, ref)
+		if err != nil {
+			if ref.Optional && apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if !found {
+			if ref.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("values reference %s/%s: key %q not found",
+				ref.Kind, ref.Name, referenceKey(ref))
+		}
+		if ref.TargetPath != "" {
+			value, err = atPath(ref.TargetPath, value)
+			if err != nil {
+				return nil, fmt.Errorf("values reference %s/%s: %w", ref.Kind, ref.Name, err)
+			}
+		}
+		out = append(out, value)
+	}
+	return out, nil
+}
+
+// fromReference returns the value of the given reference, and whether its
+// key was found. A missing object is reported as an error that satisfies
+// apierrors.IsNotFound.
+func fromReference(ctx context.Context, client client.Client, namespace string,
+	ref oceanv1beta1.ValuesReference) (string, bool, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: ref.Name}
+	switch ref.Kind {
+	case oceanv1beta1.ValuesReferenceKindConfigMap:
+		obj := new(corev1.ConfigMap)
+		if err := client.Get(ctx, key, obj); err != nil {
+			return "", false, getReferenceError(ref, err)
+		}
+		if v, ok := obj.Data[referenceKey(ref)]; ok {
+			return v, true, nil
+		}
+		if v, ok := obj.BinaryData[referenceKey(ref)]; ok {
+			return string(v), true, nil
+		}
+		return "", false, nil
+	case oceanv1beta1.ValuesReferenceKindSecret:
+		obj := new(corev1.Secret)
+		if err := client.Get(ctx, key, obj); err != nil {
+			return "", false, getReferenceError(ref, err)
+		}
+		if v, ok := obj.Data[referenceKey(ref)]; ok {
+			return string(v), true, nil
+		}
+		return "", false, nil
+	default:
+		return "", false, fmt.Errorf("unsupported values reference kind: %v", ref.Kind)
+	}
+}
+
+func getReferenceError(ref oceanv1beta1.ValuesReference, err error) error {
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("values reference %s/%s: object not found: %w", ref.Kind, ref.Name, err)
+	}
+	return fmt.Errorf("unable to get values reference %s/%s: %w", ref.Kind, ref.Name, err)
+}
+
+func referenceKey(ref oceanv1beta1.ValuesReference) string {
+	if ref.Key == "" {
+		return oceanv1beta1.DefaultValuesKey
+	}
+	return ref.Key
+}
+
+// atPath returns values holding the given value at the given dot-separated path.
+func atPath(path, value string) (string, error) {
+	keys := strings.Split(path, ".")
+	var v interface{} = value
+	for i := len(keys) - 1; i >= 0; i-- {
+		if keys[i] == "" {
+			return "", fmt.Errorf("invalid target path: %q", path)
+		}
+		v = map[string]interface{}{keys[i]: v}
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal values: %w", err)
+	}
+	return string(b), nil
+}
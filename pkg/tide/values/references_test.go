@@ -0,0 +1,75 @@
+// Copyright 2021 NetApp, Inc. All Rights Reserved.
+
+package values
+
+import (
+	"context"
+	"testing"
+
+	oceanv1beta1 "github.com/spotinst/ocean-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFromReferences(t *testing.T) {
+	client := fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: "spot-system"},
+			Data: map[string]string{
+				oceanv1beta1.DefaultValuesKey: "replicas: 1\nimage:\n  tag: v1\n",
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "spot-system"},
+			Data: map[string][]byte{
+				"token": []byte("redacted"),
+			},
+		},
+	).Build()
+
+	t.Run("whenSuccessful", func(tt *testing.T) {
+		refs, err := FromReferences(context.Background(), client, "spot-system", []oceanv1beta1.ValuesReference{
+			{Kind: oceanv1beta1.ValuesReferenceKindConfigMap, Name: "base"},
+			{Kind: oceanv1beta1.ValuesReferenceKindSecret, Name: "creds", Key: "token", TargetPath: "spotinst.token"},
+		})
+		assert.NoError(tt, err)
+		assert.Len(tt, refs, 2)
+
+		merged, err := Merge(append(refs, "image:\n  tag: v2\n")...)
+		assert.NoError(tt, err)
+
+		m := make(map[string]interface{})
+		assert.NoError(tt, decode(merged, &m))
+		assert.Equal(tt, map[string]interface{}{
+			"replicas": float64(1),
+			"image":    map[string]interface{}{"tag": "v2"},
+			"spotinst": map[string]interface{}{"token": "redacted"},
+		}, m)
+	})
+
+	t.Run("whenOptionalMissing", func(tt *testing.T) {
+		refs, err := FromReferences(context.Background(), client, "spot-system", []oceanv1beta1.ValuesReference{
+			{Kind: oceanv1beta1.ValuesReferenceKindConfigMap, Name: "missing", Optional: true},
+			{Kind: oceanv1beta1.ValuesReferenceKindSecret, Name: "creds", Key: "missing", Optional: true},
+		})
+		assert.NoError(tt, err)
+		assert.Empty(tt, refs)
+	})
+
+	t.Run("whenRequiredMissing", func(tt *testing.T) {
+		_, err := FromReferences(context.Background(), client, "spot-system", []oceanv1beta1.ValuesReference{
+			{Kind: oceanv1beta1.ValuesReferenceKindConfigMap, Name: "missing"},
+		})
+		assert.True(tt, apierrors.IsNotFound(err))
+		assert.Contains(tt, err.Error(), "object not found")
+
+		_, err = FromReferences(context.Background(), client, "spot-system", []oceanv1beta1.ValuesReference{
+			{Kind: oceanv1beta1.ValuesReferenceKindSecret, Name: "creds", Key: "missing"},
+		})
+		assert.False(tt, apierrors.IsNotFound(err))
+		assert.Contains(tt, err.Error(), "key \"missing\" not found")
+	})
+}